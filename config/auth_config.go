@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/daffaromero/gorpc-template/utils"
+)
+
+const (
+	defaultJWTIssuer     = "gorpc-template"
+	defaultJWTAccessTTL  = 15 * time.Minute
+	defaultJWTRefreshTTL = 30 * 24 * time.Hour
+)
+
+// AuthConfig holds the JWT settings. The signing secrets are read through
+// utils.GetEnv, so they can live in Vault instead of the environment.
+type AuthConfig struct {
+	Issuer        string
+	AccessSecret  string
+	RefreshSecret string
+	AccessTTL     time.Duration
+	RefreshTTL    time.Duration
+}
+
+func LoadAuthConfig() (*AuthConfig, error) {
+	accessSecret := utils.GetEnv("JWT_ACCESS_SECRET")
+	refreshSecret := utils.GetEnv("JWT_REFRESH_SECRET")
+	if accessSecret == "" || refreshSecret == "" {
+		return nil, fmt.Errorf("JWT_ACCESS_SECRET and JWT_REFRESH_SECRET must be set")
+	}
+
+	accessTTL, err := durationEnv("JWT_ACCESS_TTL", defaultJWTAccessTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshTTL, err := durationEnv("JWT_REFRESH_TTL", defaultJWTRefreshTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := utils.GetEnv("JWT_ISSUER")
+	if issuer == "" {
+		issuer = defaultJWTIssuer
+	}
+
+	return &AuthConfig{
+		Issuer:        issuer,
+		AccessSecret:  accessSecret,
+		RefreshSecret: refreshSecret,
+		AccessTTL:     accessTTL,
+		RefreshTTL:    refreshTTL,
+	}, nil
+}
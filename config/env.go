@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/daffaromero/gorpc-template/utils"
+)
+
+// durationEnv reads a duration such as "30s" from key, or returns fallback
+// when it is unset. Every duration configured this way is a period, TTL or
+// delay, so zero and negative values are rejected.
+func durationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := utils.GetEnv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("invalid %s: %q is not positive", key, value)
+	}
+
+	return duration, nil
+}
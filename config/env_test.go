@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDurationEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"unset", "", time.Minute, false},
+		{"set", "250ms", 250 * time.Millisecond, false},
+		{"not a duration", "soon", 0, true},
+		{"missing unit", "30", 0, true},
+		{"zero", "0s", 0, true},
+		{"negative", "-1s", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_DURATION", tt.value)
+
+			got, err := durationEnv("TEST_DURATION", time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("durationEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("durationEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/daffaromero/gorpc-template/utils"
+)
+
+const (
+	EventBusMemory = "memory"
+	EventBusNATS   = "nats"
+
+	defaultNATSStream = "EVENTS"
+)
+
+type EventsConfig struct {
+	Bus        string
+	NATSURL    string
+	NATSStream string
+}
+
+func LoadEventsConfig() (*EventsConfig, error) {
+	bus := utils.GetEnv("EVENT_BUS")
+	if bus == "" {
+		bus = EventBusMemory
+	}
+
+	config := &EventsConfig{Bus: bus}
+
+	switch bus {
+	case EventBusMemory:
+	case EventBusNATS:
+		config.NATSURL = utils.GetEnv("NATS_URL")
+		if config.NATSURL == "" {
+			return nil, fmt.Errorf("NATS_URL must be set when EVENT_BUS is %q", EventBusNATS)
+		}
+
+		config.NATSStream = utils.GetEnv("NATS_STREAM")
+		if config.NATSStream == "" {
+			config.NATSStream = defaultNATSStream
+		}
+	default:
+		return nil, fmt.Errorf("invalid EVENT_BUS %q", bus)
+	}
+
+	return config, nil
+}
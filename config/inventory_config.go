@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/daffaromero/gorpc-template/utils"
+)
+
+const defaultLowStockThreshold = 5
+
+type InventoryConfig struct {
+	// LowStockThreshold is the stock level at or below which an
+	// ItemLowStock event is emitted.
+	LowStockThreshold int32
+}
+
+func LoadInventoryConfig() (*InventoryConfig, error) {
+	threshold := defaultLowStockThreshold
+	if value := utils.GetEnv("LOW_STOCK_THRESHOLD"); value != "" {
+		var err error
+		threshold, err = strconv.Atoi(value)
+		if err != nil || threshold < 0 {
+			return nil, fmt.Errorf("invalid LOW_STOCK_THRESHOLD: %q", value)
+		}
+	}
+
+	return &InventoryConfig{LowStockThreshold: int32(threshold)}, nil
+}
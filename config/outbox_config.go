@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/daffaromero/gorpc-template/utils"
+)
+
+const (
+	defaultOutboxPollInterval = time.Second
+	defaultOutboxBatchSize    = 100
+)
+
+type OutboxConfig struct {
+	PollInterval time.Duration
+	BatchSize    int32
+}
+
+func LoadOutboxConfig() (*OutboxConfig, error) {
+	pollInterval, err := durationEnv("OUTBOX_POLL_INTERVAL", defaultOutboxPollInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := defaultOutboxBatchSize
+	if value := utils.GetEnv("OUTBOX_BATCH_SIZE"); value != "" {
+		batchSize, err = strconv.Atoi(value)
+		if err != nil || batchSize <= 0 {
+			return nil, fmt.Errorf("invalid OUTBOX_BATCH_SIZE: %q", value)
+		}
+	}
+
+	return &OutboxConfig{
+		PollInterval: pollInterval,
+		BatchSize:    int32(batchSize),
+	}, nil
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadOutboxConfig(t *testing.T) {
+	t.Setenv("OUTBOX_POLL_INTERVAL", "")
+	t.Setenv("OUTBOX_BATCH_SIZE", "")
+
+	outboxConfig, err := LoadOutboxConfig()
+	if err != nil {
+		t.Fatalf("LoadOutboxConfig() = %v", err)
+	}
+	if outboxConfig.PollInterval != defaultOutboxPollInterval || outboxConfig.BatchSize != defaultOutboxBatchSize {
+		t.Errorf("LoadOutboxConfig() = %+v, want the defaults", outboxConfig)
+	}
+
+	t.Setenv("OUTBOX_POLL_INTERVAL", "5s")
+	t.Setenv("OUTBOX_BATCH_SIZE", "10")
+
+	outboxConfig, err = LoadOutboxConfig()
+	if err != nil {
+		t.Fatalf("LoadOutboxConfig() = %v", err)
+	}
+	if outboxConfig.PollInterval != 5*time.Second || outboxConfig.BatchSize != 10 {
+		t.Errorf("LoadOutboxConfig() = %+v, want 5s and 10", outboxConfig)
+	}
+}
+
+func TestLoadOutboxConfigInvalid(t *testing.T) {
+	tests := []struct {
+		key, value string
+	}{
+		{"OUTBOX_POLL_INTERVAL", "0s"},
+		{"OUTBOX_POLL_INTERVAL", "-1s"},
+		{"OUTBOX_BATCH_SIZE", "0"},
+		{"OUTBOX_BATCH_SIZE", "many"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			t.Setenv("OUTBOX_POLL_INTERVAL", "")
+			t.Setenv("OUTBOX_BATCH_SIZE", "")
+			t.Setenv(tt.key, tt.value)
+
+			_, err := LoadOutboxConfig()
+			if err == nil || !strings.Contains(err.Error(), "invalid "+tt.key) {
+				t.Errorf("LoadOutboxConfig() = %v, want an invalid %s error", err, tt.key)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/daffaromero/gorpc-template/utils"
+)
+
+const defaultBcryptCost = 12
+
+type PasswordConfig struct {
+	BcryptCost int
+}
+
+func LoadPasswordConfig() (*PasswordConfig, error) {
+	cost := defaultBcryptCost
+
+	if value := utils.GetEnv("PASSWORD_BCRYPT_COST"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PASSWORD_BCRYPT_COST: %w", err)
+		}
+		cost = parsed
+	}
+
+	return &PasswordConfig{
+		BcryptCost: cost,
+	}, nil
+}
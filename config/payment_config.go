@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/daffaromero/gorpc-template/utils"
+)
+
+const (
+	PaymentProviderFake = "fake"
+
+	defaultPaymentFakeBaseURL = "http://localhost:8081/pay"
+)
+
+// PaymentConfig selects the payment provider. Only the fake provider ships
+// with the template; its secret signs payment callbacks.
+type PaymentConfig struct {
+	Provider    string
+	FakeBaseURL string
+	FakeSecret  string
+}
+
+func LoadPaymentConfig() (*PaymentConfig, error) {
+	provider := utils.GetEnv("PAYMENT_PROVIDER")
+	if provider == "" {
+		provider = PaymentProviderFake
+	}
+
+	if provider != PaymentProviderFake {
+		return nil, fmt.Errorf("invalid PAYMENT_PROVIDER %q", provider)
+	}
+
+	baseURL := utils.GetEnv("PAYMENT_FAKE_BASE_URL")
+	if baseURL == "" {
+		baseURL = defaultPaymentFakeBaseURL
+	}
+
+	secret := utils.GetEnv("PAYMENT_FAKE_SECRET")
+	if secret == "" {
+		return nil, fmt.Errorf("PAYMENT_FAKE_SECRET must be set when PAYMENT_PROVIDER is %q", PaymentProviderFake)
+	}
+
+	return &PaymentConfig{
+		Provider:    provider,
+		FakeBaseURL: baseURL,
+		FakeSecret:  secret,
+	}, nil
+}
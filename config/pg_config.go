@@ -14,6 +14,17 @@ import (
 	"github.com/daffaromero/gorpc-template/utils"
 )
 
+const (
+	defaultTxMaxRetries     = 3
+	defaultTxRetryBaseDelay = 20 * time.Millisecond
+	// maxTxRetries bounds DB_TX_MAX_RETRIES. Retries are only worth it while
+	// a collision is likely to clear, and each one holds up the request.
+	maxTxRetries = 10
+
+	defaultReplicaMaxLag        = 5 * time.Second
+	defaultReplicaCheckInterval = 5 * time.Second
+)
+
 type DBConfig struct {
 	Host            string
 	Port            string
@@ -23,9 +34,21 @@ type DBConfig struct {
 	MinConns        int32
 	MaxConns        int32
 	TimeOutDuration time.Duration
+	// Serialization failures and deadlocks are retried up to TxMaxRetries
+	// times, with a delay that starts around TxRetryBaseDelay and doubles.
+	TxMaxRetries     int
+	TxRetryBaseDelay time.Duration
+	// When ReplicaHost is set, reads outside transactions go to that replica
+	// while it answers health checks, run every ReplicaCheckInterval, and lags
+	// the primary by at most ReplicaMaxLag. It shares the primary's
+	// credentials and database name.
+	ReplicaHost          string
+	ReplicaPort          string
+	ReplicaMaxLag        time.Duration
+	ReplicaCheckInterval time.Duration
 }
 
-func loadDBConfig() (*DBConfig, error) {
+func LoadDBConfig() (*DBConfig, error) {
 	minConns, err := strconv.Atoi(utils.GetEnv("DB_MIN_CONNS"))
 	if err != nil {
 		return nil, fmt.Errorf("invalid DB_MIN_CONNS: %w", err)
@@ -41,37 +64,68 @@ func loadDBConfig() (*DBConfig, error) {
 		return nil, fmt.Errorf("invalid DB_CONNECTION_TIMEOUT: %w", err)
 	}
 
+	txMaxRetries := defaultTxMaxRetries
+	if value := utils.GetEnv("DB_TX_MAX_RETRIES"); value != "" {
+		txMaxRetries, err = strconv.Atoi(value)
+		if err != nil || txMaxRetries < 0 || txMaxRetries > maxTxRetries {
+			return nil, fmt.Errorf("invalid DB_TX_MAX_RETRIES: %q", value)
+		}
+	}
+
+	txRetryBaseDelay, err := durationEnv("DB_TX_RETRY_BASE_DELAY", defaultTxRetryBaseDelay)
+	if err != nil {
+		return nil, err
+	}
+
+	// The replica settings only matter, and are only checked, when a
+	// replica is configured.
+	var replicaPort string
+	var replicaMaxLag, replicaCheckInterval time.Duration
+	replicaHost := utils.GetEnv("DB_REPLICA_HOST")
+	if replicaHost != "" {
+		replicaPort = utils.GetEnv("DB_REPLICA_PORT")
+		if replicaPort == "" {
+			replicaPort = utils.GetEnv("DB_PORT")
+		}
+
+		replicaMaxLag, err = durationEnv("DB_REPLICA_MAX_LAG", defaultReplicaMaxLag)
+		if err != nil {
+			return nil, err
+		}
+
+		replicaCheckInterval, err = durationEnv("DB_REPLICA_CHECK_INTERVAL", defaultReplicaCheckInterval)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &DBConfig{
-		Host:            utils.GetEnv("DB_HOST"),
-		Port:            utils.GetEnv("DB_PORT"),
-		Username:        utils.GetEnv("DB_USERNAME"),
-		Password:        utils.GetEnv("DB_PASSWORD"),
-		DBName:          utils.GetEnv("DB_NAME"),
-		MinConns:        int32(minConns),
-		MaxConns:        int32(maxConns),
-		TimeOutDuration: time.Duration(timeoutDuration) * time.Second,
+		Host:             utils.GetEnv("DB_HOST"),
+		Port:             utils.GetEnv("DB_PORT"),
+		Username:         utils.GetEnv("DB_USERNAME"),
+		Password:         utils.GetEnv("DB_PASSWORD"),
+		DBName:           utils.GetEnv("DB_NAME"),
+		MinConns:         int32(minConns),
+		MaxConns:         int32(maxConns),
+		TimeOutDuration:  time.Duration(timeoutDuration) * time.Second,
+		TxMaxRetries:     txMaxRetries,
+		TxRetryBaseDelay: txRetryBaseDelay,
+
+		ReplicaHost:          replicaHost,
+		ReplicaPort:          replicaPort,
+		ReplicaMaxLag:        replicaMaxLag,
+		ReplicaCheckInterval: replicaCheckInterval,
 	}, nil
 }
 
-func NewPostgresDatabase() (*pgxpool.Pool, error) {
+func NewPostgresDatabase(dbConfig *DBConfig) (*pgxpool.Pool, error) {
 	logger := logs.New("database_connection")
 
-	dbConfig, err := loadDBConfig()
+	poolConfig, err := newPoolConfig(dbConfig, dbConfig.Host, dbConfig.Port)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load database configuration: %w", err)
+		return nil, err
 	}
 
-	dsn := fmt.Sprintf("postgresql://%s:%s@%s:%s/%s", dbConfig.Username, dbConfig.Password, dbConfig.Host, dbConfig.Port, dbConfig.DBName)
-
-	poolConfig, err := pgxpool.ParseConfig(dsn)
-	if err != nil {
-		logger.Error("Failed to parse configuration dsn " + dsn)
-	}
-
-	poolConfig.MinConns = dbConfig.MinConns
-	poolConfig.MaxConns = dbConfig.MaxConns
-	poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeDescribeExec
-
 	ctx, cancel := context.WithTimeout(context.Background(), dbConfig.TimeOutDuration)
 	defer cancel()
 
@@ -84,7 +138,53 @@ func NewPostgresDatabase() (*pgxpool.Pool, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	logger.Info("Database connected dsn " + dsn)
+	logger.Info("Database connected to %s", describeDatabase(poolConfig))
 
 	return pool, nil
 }
+
+// NewReplicaDatabase returns a pool for the replica at dbConfig.ReplicaHost.
+// Unlike NewPostgresDatabase it does not ping: an unreachable replica only
+// means that reads stay on the primary until it comes up.
+func NewReplicaDatabase(dbConfig *DBConfig) (*pgxpool.Pool, error) {
+	logger := logs.New("database_connection")
+
+	poolConfig, err := newPoolConfig(dbConfig, dbConfig.ReplicaHost, dbConfig.ReplicaPort)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create replica connection pool: %w", err)
+	}
+
+	logger.Info("Replica configured at %s", describeDatabase(poolConfig))
+
+	return pool, nil
+}
+
+func newPoolConfig(dbConfig *DBConfig, host, port string) (*pgxpool.Config, error) {
+	logger := logs.New("database_connection")
+
+	dsn := fmt.Sprintf("postgresql://%s:%s@%s:%s/%s", dbConfig.Username, dbConfig.Password, host, port, dbConfig.DBName)
+
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		logger.Error("Failed to parse configuration for %s:%s/%s", host, port, dbConfig.DBName)
+		return nil, fmt.Errorf("failed to parse database configuration: %w", err)
+	}
+
+	poolConfig.MinConns = dbConfig.MinConns
+	poolConfig.MaxConns = dbConfig.MaxConns
+	poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeDescribeExec
+
+	return poolConfig, nil
+}
+
+// describeDatabase names the database of poolConfig for logs, leaving out the
+// credentials.
+func describeDatabase(poolConfig *pgxpool.Config) string {
+	connConfig := poolConfig.ConnConfig
+	return fmt.Sprintf("%s:%d/%s", connConfig.Host, connConfig.Port, connConfig.Database)
+}
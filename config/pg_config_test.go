@@ -0,0 +1,155 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// setDBEnv sets the connection settings LoadDBConfig requires and clears the
+// optional ones.
+func setDBEnv(t *testing.T) {
+	t.Helper()
+
+	t.Setenv("DB_MIN_CONNS", "1")
+	t.Setenv("DB_MAX_CONNS", "4")
+	t.Setenv("DB_CONNECTION_TIMEOUT", "5")
+	t.Setenv("DB_TX_MAX_RETRIES", "")
+	t.Setenv("DB_TX_RETRY_BASE_DELAY", "")
+	t.Setenv("DB_REPLICA_HOST", "")
+	t.Setenv("DB_REPLICA_PORT", "")
+	t.Setenv("DB_REPLICA_MAX_LAG", "")
+	t.Setenv("DB_REPLICA_CHECK_INTERVAL", "")
+}
+
+func TestLoadDBConfigRetries(t *testing.T) {
+	setDBEnv(t)
+
+	dbConfig, err := LoadDBConfig()
+	if err != nil {
+		t.Fatalf("LoadDBConfig() = %v", err)
+	}
+	if dbConfig.TxMaxRetries != defaultTxMaxRetries || dbConfig.TxRetryBaseDelay != defaultTxRetryBaseDelay {
+		t.Errorf("LoadDBConfig() = %+v, want the default retries", dbConfig)
+	}
+
+	t.Setenv("DB_TX_MAX_RETRIES", "0")
+	t.Setenv("DB_TX_RETRY_BASE_DELAY", "50ms")
+
+	dbConfig, err = LoadDBConfig()
+	if err != nil {
+		t.Fatalf("LoadDBConfig() = %v", err)
+	}
+	if dbConfig.TxMaxRetries != 0 || dbConfig.TxRetryBaseDelay != 50*time.Millisecond {
+		t.Errorf("LoadDBConfig() = %+v, want 0 retries and 50ms", dbConfig)
+	}
+}
+
+func TestLoadDBConfigInvalid(t *testing.T) {
+	tests := []struct {
+		key, value string
+	}{
+		{"DB_TX_MAX_RETRIES", "-1"},
+		{"DB_TX_MAX_RETRIES", "11"},
+		{"DB_TX_MAX_RETRIES", "many"},
+		{"DB_TX_RETRY_BASE_DELAY", "0s"},
+		{"DB_TX_RETRY_BASE_DELAY", "-20ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			setDBEnv(t)
+			t.Setenv(tt.key, tt.value)
+
+			_, err := LoadDBConfig()
+			if err == nil || !strings.Contains(err.Error(), "invalid "+tt.key) {
+				t.Errorf("LoadDBConfig() = %v, want an invalid %s error", err, tt.key)
+			}
+		})
+	}
+}
+
+func TestLoadDBConfigReplica(t *testing.T) {
+	setDBEnv(t)
+	t.Setenv("DB_PORT", "5432")
+	t.Setenv("DB_REPLICA_HOST", "replica.internal")
+
+	dbConfig, err := LoadDBConfig()
+	if err != nil {
+		t.Fatalf("LoadDBConfig() = %v", err)
+	}
+	if dbConfig.ReplicaPort != "5432" || dbConfig.ReplicaMaxLag != defaultReplicaMaxLag || dbConfig.ReplicaCheckInterval != defaultReplicaCheckInterval {
+		t.Errorf("LoadDBConfig() = %+v, want the primary's port and the default replica checks", dbConfig)
+	}
+
+	t.Setenv("DB_REPLICA_PORT", "6432")
+	t.Setenv("DB_REPLICA_MAX_LAG", "2s")
+	t.Setenv("DB_REPLICA_CHECK_INTERVAL", "500ms")
+
+	dbConfig, err = LoadDBConfig()
+	if err != nil {
+		t.Fatalf("LoadDBConfig() = %v", err)
+	}
+	if dbConfig.ReplicaPort != "6432" || dbConfig.ReplicaMaxLag != 2*time.Second || dbConfig.ReplicaCheckInterval != 500*time.Millisecond {
+		t.Errorf("LoadDBConfig() = %+v, want 6432, 2s and 500ms", dbConfig)
+	}
+}
+
+func TestLoadDBConfigReplicaInvalid(t *testing.T) {
+	tests := []struct {
+		key, value string
+	}{
+		{"DB_REPLICA_MAX_LAG", "0s"},
+		{"DB_REPLICA_MAX_LAG", "-5s"},
+		{"DB_REPLICA_CHECK_INTERVAL", "0s"},
+		{"DB_REPLICA_CHECK_INTERVAL", "-1s"},
+		{"DB_REPLICA_CHECK_INTERVAL", "often"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			setDBEnv(t)
+			t.Setenv("DB_REPLICA_HOST", "replica.internal")
+			t.Setenv(tt.key, tt.value)
+
+			_, err := LoadDBConfig()
+			if err == nil || !strings.Contains(err.Error(), "invalid "+tt.key) {
+				t.Errorf("LoadDBConfig() = %v, want an invalid %s error", err, tt.key)
+			}
+		})
+	}
+}
+
+func TestLoadDBConfigWithoutReplica(t *testing.T) {
+	setDBEnv(t)
+	t.Setenv("DB_REPLICA_MAX_LAG", "0s")
+
+	dbConfig, err := LoadDBConfig()
+	if err != nil {
+		t.Fatalf("LoadDBConfig() = %v, want replica settings ignored without DB_REPLICA_HOST", err)
+	}
+	if dbConfig.ReplicaHost != "" {
+		t.Errorf("ReplicaHost = %q, want none", dbConfig.ReplicaHost)
+	}
+}
+
+func TestNewPoolConfigDescription(t *testing.T) {
+	dbConfig := &DBConfig{Username: "app", Password: "s3cret", DBName: "shop", MinConns: 1, MaxConns: 4}
+
+	poolConfig, err := newPoolConfig(dbConfig, "replica.internal", "6432")
+	if err != nil {
+		t.Fatalf("newPoolConfig() = %v", err)
+	}
+
+	if poolConfig.MinConns != 1 || poolConfig.MaxConns != 4 {
+		t.Errorf("pool size = %d..%d, want 1..4", poolConfig.MinConns, poolConfig.MaxConns)
+	}
+
+	description := describeDatabase(poolConfig)
+	if description != "replica.internal:6432/shop" {
+		t.Errorf("describeDatabase() = %q", description)
+	}
+	if strings.Contains(description, "s3cret") || strings.Contains(description, "app") {
+		t.Errorf("describeDatabase() = %q leaks credentials", description)
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"time"
+)
+
+const (
+	defaultPurgeRetention = 30 * 24 * time.Hour
+	defaultPurgeInterval  = time.Hour
+)
+
+// PurgeConfig controls when soft-deleted rows are removed for good.
+type PurgeConfig struct {
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+func LoadPurgeConfig() (*PurgeConfig, error) {
+	retention, err := durationEnv("PURGE_RETENTION", defaultPurgeRetention)
+	if err != nil {
+		return nil, err
+	}
+
+	interval, err := durationEnv("PURGE_INTERVAL", defaultPurgeInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PurgeConfig{
+		Retention: retention,
+		Interval:  interval,
+	}, nil
+}
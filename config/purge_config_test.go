@@ -0,0 +1,56 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadPurgeConfig(t *testing.T) {
+	t.Setenv("PURGE_RETENTION", "")
+	t.Setenv("PURGE_INTERVAL", "")
+
+	purgeConfig, err := LoadPurgeConfig()
+	if err != nil {
+		t.Fatalf("LoadPurgeConfig() = %v", err)
+	}
+	if purgeConfig.Retention != defaultPurgeRetention || purgeConfig.Interval != defaultPurgeInterval {
+		t.Errorf("LoadPurgeConfig() = %+v, want the defaults", purgeConfig)
+	}
+
+	t.Setenv("PURGE_RETENTION", "72h")
+	t.Setenv("PURGE_INTERVAL", "10m")
+
+	purgeConfig, err = LoadPurgeConfig()
+	if err != nil {
+		t.Fatalf("LoadPurgeConfig() = %v", err)
+	}
+	if purgeConfig.Retention != 72*time.Hour || purgeConfig.Interval != 10*time.Minute {
+		t.Errorf("LoadPurgeConfig() = %+v, want 72h and 10m", purgeConfig)
+	}
+}
+
+func TestLoadPurgeConfigInvalid(t *testing.T) {
+	tests := []struct {
+		key, value string
+	}{
+		{"PURGE_RETENTION", "0s"},
+		{"PURGE_RETENTION", "-24h"},
+		{"PURGE_INTERVAL", "0s"},
+		{"PURGE_INTERVAL", "-1m"},
+		{"PURGE_INTERVAL", "hourly"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			t.Setenv("PURGE_RETENTION", "")
+			t.Setenv("PURGE_INTERVAL", "")
+			t.Setenv(tt.key, tt.value)
+
+			_, err := LoadPurgeConfig()
+			if err == nil || !strings.Contains(err.Error(), "invalid "+tt.key) {
+				t.Errorf("LoadPurgeConfig() = %v, want an invalid %s error", err, tt.key)
+			}
+		})
+	}
+}
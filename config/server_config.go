@@ -0,0 +1,34 @@
+package config
+
+import (
+	"github.com/daffaromero/gorpc-template/utils"
+)
+
+const (
+	defaultGRPCAddress  = ":50051"
+	defaultAdminAddress = "127.0.0.1:9090"
+)
+
+type ServerConfig struct {
+	GRPCAddress string
+	// AdminAddress serves internal endpoints such as /debug/vars. It listens
+	// on loopback by default and must not be exposed publicly.
+	AdminAddress string
+}
+
+func LoadServerConfig() *ServerConfig {
+	address := utils.GetEnv("GRPC_ADDRESS")
+	if address == "" {
+		address = defaultGRPCAddress
+	}
+
+	adminAddress := utils.GetEnv("ADMIN_ADDRESS")
+	if adminAddress == "" {
+		adminAddress = defaultAdminAddress
+	}
+
+	return &ServerConfig{
+		GRPCAddress:  address,
+		AdminAddress: adminAddress,
+	}
+}
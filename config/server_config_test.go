@@ -0,0 +1,32 @@
+package config
+
+import "testing"
+
+func TestLoadServerConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		grpc      string
+		admin     string
+		wantGRPC  string
+		wantAdmin string
+	}{
+		{"defaults", "", "", defaultGRPCAddress, defaultAdminAddress},
+		{"overrides", ":6000", "127.0.0.1:7000", ":6000", "127.0.0.1:7000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GRPC_ADDRESS", tt.grpc)
+			t.Setenv("ADMIN_ADDRESS", tt.admin)
+
+			serverConfig := LoadServerConfig()
+
+			if serverConfig.GRPCAddress != tt.wantGRPC {
+				t.Errorf("GRPCAddress = %q, want %q", serverConfig.GRPCAddress, tt.wantGRPC)
+			}
+			if serverConfig.AdminAddress != tt.wantAdmin {
+				t.Errorf("AdminAddress = %q, want %q", serverConfig.AdminAddress, tt.wantAdmin)
+			}
+		})
+	}
+}
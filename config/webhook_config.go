@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/daffaromero/gorpc-template/utils"
+)
+
+const defaultWebhookAddress = ":8080"
+
+// WebhookConfig holds the settings of the HTTP listener that receives
+// payment provider webhooks.
+type WebhookConfig struct {
+	Address       string
+	PaymentSecret string
+}
+
+func LoadWebhookConfig() (*WebhookConfig, error) {
+	secret := utils.GetEnv("PAYMENT_WEBHOOK_SECRET")
+	if secret == "" {
+		return nil, fmt.Errorf("PAYMENT_WEBHOOK_SECRET must be set")
+	}
+
+	address := utils.GetEnv("WEBHOOK_ADDRESS")
+	if address == "" {
+		address = defaultWebhookAddress
+	}
+
+	return &WebhookConfig{
+		Address:       address,
+		PaymentSecret: secret,
+	}, nil
+}
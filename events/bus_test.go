@@ -0,0 +1,211 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+)
+
+const deliveryTimeout = 5 * time.Second
+
+// recorder is a Handler that records the IDs of the events it handled.
+type recorder struct {
+	mu       sync.Mutex
+	ids      []string
+	received chan string
+}
+
+func newRecorder() *recorder {
+	return &recorder{received: make(chan string, 100)}
+}
+
+func (r *recorder) handle(ctx context.Context, envelope *api.EventEnvelope) error {
+	r.mu.Lock()
+	r.ids = append(r.ids, envelope.GetId())
+	r.mu.Unlock()
+	r.received <- envelope.GetId()
+	return nil
+}
+
+func (r *recorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.ids)
+}
+
+// waitFor waits until n events have been received by any of recorders in
+// total and returns their IDs.
+func waitFor(t *testing.T, n int, recorders ...*recorder) []string {
+	t.Helper()
+
+	received := make(chan string)
+	done := make(chan struct{})
+	defer close(done)
+	for _, r := range recorders {
+		go func(r *recorder) {
+			for {
+				select {
+				case id := <-r.received:
+					select {
+					case received <- id:
+					case <-done:
+						return
+					}
+				case <-done:
+					return
+				}
+			}
+		}(r)
+	}
+
+	var ids []string
+	timeout := time.After(deliveryTimeout)
+	for len(ids) < n {
+		select {
+		case id := <-received:
+			ids = append(ids, id)
+		case <-timeout:
+			t.Fatalf("received %d events, want %d", len(ids), n)
+		}
+	}
+	return ids
+}
+
+// assertQuiet fails if any recorder receives another event shortly.
+func assertQuiet(t *testing.T, recorders ...*recorder) {
+	t.Helper()
+
+	time.Sleep(200 * time.Millisecond)
+	for _, r := range recorders {
+		select {
+		case id := <-r.received:
+			t.Fatalf("unexpected event %s", id)
+		default:
+		}
+	}
+}
+
+func envelopeWithID(id string) *api.EventEnvelope {
+	return &api.EventEnvelope{Id: id, Type: "OrderCreated", AggregateType: "order", AggregateId: "order-1"}
+}
+
+func publish(t *testing.T, bus Bus, topic string, ids ...string) {
+	t.Helper()
+
+	for _, id := range ids {
+		if err := bus.Publish(context.Background(), topic, envelopeWithID(id)); err != nil {
+			t.Fatalf("Publish(%s) = %v", id, err)
+		}
+	}
+}
+
+func subscribe(t *testing.T, bus Bus, pattern, group string, handler Handler) Subscription {
+	t.Helper()
+
+	sub, err := bus.Subscribe(context.Background(), pattern, group, handler)
+	if err != nil {
+		t.Fatalf("Subscribe(%s, %s) = %v", pattern, group, err)
+	}
+	t.Cleanup(func() { _ = sub.Unsubscribe() })
+	return sub
+}
+
+// testBus runs the behaviour every Bus implementation must share. newBus
+// returns a fresh bus for each subtest.
+func testBus(t *testing.T, newBus func(t *testing.T) Bus) {
+	topic := Topic("order", "OrderCreated")
+
+	t.Run("every group receives every event", func(t *testing.T) {
+		bus := newBus(t)
+		billing, shipping := newRecorder(), newRecorder()
+		subscribe(t, bus, AllTopics(), "billing", billing.handle)
+		subscribe(t, bus, AggregateTopics("order"), "shipping", shipping.handle)
+
+		publish(t, bus, topic, "e1", "e2")
+
+		waitFor(t, 2, billing)
+		waitFor(t, 2, shipping)
+	})
+
+	t.Run("each event is handled once per group", func(t *testing.T) {
+		bus := newBus(t)
+		first, second := newRecorder(), newRecorder()
+		subscribe(t, bus, AllTopics(), "billing", first.handle)
+		subscribe(t, bus, AllTopics(), "billing", second.handle)
+
+		var ids []string
+		for i := 0; i < 10; i++ {
+			ids = append(ids, fmt.Sprintf("e%d", i))
+		}
+		publish(t, bus, topic, ids...)
+
+		got := waitFor(t, len(ids), first, second)
+		seen := make(map[string]bool)
+		for _, id := range got {
+			if seen[id] {
+				t.Fatalf("event %s handled twice in one group", id)
+			}
+			seen[id] = true
+		}
+		assertQuiet(t, first, second)
+	})
+
+	t.Run("patterns filter topics", func(t *testing.T) {
+		bus := newBus(t)
+		items := newRecorder()
+		subscribe(t, bus, AggregateTopics("item"), "catalog", items.handle)
+
+		publish(t, bus, Topic("order", "OrderCreated"), "order-event")
+		publish(t, bus, Topic("item", "ItemCreated"), "item-event")
+
+		if got := waitFor(t, 1, items); got[0] != "item-event" {
+			t.Fatalf("received %s, want item-event", got[0])
+		}
+		assertQuiet(t, items)
+	})
+
+	t.Run("failed events are delivered again", func(t *testing.T) {
+		bus := newBus(t)
+		var mu sync.Mutex
+		attempts := 0
+		delivered := make(chan struct{}, 1)
+		subscribe(t, bus, AllTopics(), "flaky", func(ctx context.Context, envelope *api.EventEnvelope) error {
+			mu.Lock()
+			defer mu.Unlock()
+			attempts++
+			if attempts < 3 {
+				return errors.New("try again")
+			}
+			delivered <- struct{}{}
+			return nil
+		})
+
+		publish(t, bus, topic, "e1")
+
+		select {
+		case <-delivered:
+		case <-time.After(deliveryTimeout):
+			t.Fatal("event was not delivered again")
+		}
+	})
+
+	t.Run("unsubscribed handlers receive nothing", func(t *testing.T) {
+		bus := newBus(t)
+		r := newRecorder()
+		sub := subscribe(t, bus, AllTopics(), "billing", r.handle)
+
+		publish(t, bus, topic, "e1")
+		waitFor(t, 1, r)
+
+		if err := sub.Unsubscribe(); err != nil {
+			t.Fatalf("Unsubscribe() = %v", err)
+		}
+		publish(t, bus, topic, "e2")
+		assertQuiet(t, r)
+	})
+}
@@ -0,0 +1,76 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+)
+
+const topicPrefix = "events"
+
+var ErrClosed = errors.New("event bus is closed")
+
+// Handler processes one event. Returning an error asks the bus to deliver the
+// event again later, so handlers must be idempotent.
+type Handler func(ctx context.Context, envelope *api.EventEnvelope) error
+
+type Publisher interface {
+	Publish(ctx context.Context, topic string, envelope *api.EventEnvelope) error
+}
+
+// Subscriber delivers events published on topics matching pattern. Every
+// consumer group receives each event, and within a group each event is
+// handled by only one of its subscriptions.
+type Subscriber interface {
+	Subscribe(ctx context.Context, pattern, group string, handler Handler) (Subscription, error)
+}
+
+type Subscription interface {
+	Unsubscribe() error
+}
+
+type Bus interface {
+	Publisher
+	Subscriber
+	Close() error
+}
+
+// Topic names the topic of an event as "events.<aggregate>.<event type>",
+// for example "events.order.OrderCreated".
+func Topic(aggregateType, eventType string) string {
+	return topicPrefix + "." + aggregateType + "." + eventType
+}
+
+// AggregateTopics matches every event of an aggregate type.
+func AggregateTopics(aggregateType string) string {
+	return topicPrefix + "." + aggregateType + ".*"
+}
+
+// AllTopics matches every event.
+func AllTopics() string {
+	return topicPrefix + ".>"
+}
+
+// Match reports whether topic matches pattern. Patterns use NATS subject
+// syntax: "*" matches exactly one token and a trailing ">" matches one or
+// more tokens.
+func Match(pattern, topic string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	topicTokens := strings.Split(topic, ".")
+
+	for i, token := range patternTokens {
+		if token == ">" {
+			return i == len(patternTokens)-1 && len(topicTokens) > i
+		}
+		if i >= len(topicTokens) {
+			return false
+		}
+		if token != "*" && token != topicTokens[i] {
+			return false
+		}
+	}
+
+	return len(patternTokens) == len(topicTokens)
+}
@@ -0,0 +1,41 @@
+package events
+
+import "testing"
+
+func TestTopic(t *testing.T) {
+	if got, want := Topic("order", "OrderCreated"), "events.order.OrderCreated"; got != want {
+		t.Errorf("Topic() = %q, want %q", got, want)
+	}
+	if got, want := AggregateTopics("order"), "events.order.*"; got != want {
+		t.Errorf("AggregateTopics() = %q, want %q", got, want)
+	}
+	if got, want := AllTopics(), "events.>"; got != want {
+		t.Errorf("AllTopics() = %q, want %q", got, want)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, topic string
+		want           bool
+	}{
+		{"events.order.OrderCreated", "events.order.OrderCreated", true},
+		{"events.order.OrderCreated", "events.order.OrderUpdated", false},
+		{"events.order.*", "events.order.OrderCreated", true},
+		{"events.order.*", "events.item.ItemCreated", false},
+		{"events.order.*", "events.order", false},
+		{"events.order.*", "events.order.OrderCreated.extra", false},
+		{"events.*.OrderCreated", "events.order.OrderCreated", true},
+		{"events.>", "events.order.OrderCreated", true},
+		{"events.>", "events.order", true},
+		{"events.>", "events", false},
+		{"events.>.OrderCreated", "events.order.OrderCreated", false},
+		{"events.order", "events.order.OrderCreated", false},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.topic); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.topic, got, tt.want)
+		}
+	}
+}
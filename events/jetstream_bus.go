@@ -0,0 +1,119 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"google.golang.org/protobuf/proto"
+
+	logs "github.com/daffaromero/gorpc-template/helper/logger"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+)
+
+const (
+	jetStreamDuplicateWindow = 2 * time.Minute
+	jetStreamAckWait         = 30 * time.Second
+	jetStreamMaxDeliver      = 5
+)
+
+var durableReplacer = strings.NewReplacer(".", "_", "*", "any", ">", "all", " ", "_")
+
+type jetStreamBus struct {
+	nc     *nats.Conn
+	js     jetstream.JetStream
+	stream string
+	logger *logs.Log
+}
+
+type jetStreamSubscription struct {
+	consumeContext jetstream.ConsumeContext
+}
+
+// NewJetStreamBus returns a Bus backed by a NATS JetStream stream covering
+// every topic. The stream is created if it does not exist. The bus takes
+// ownership of nc and drains it on Close.
+//
+// Envelope IDs are sent as Nats-Msg-Id, so JetStream drops duplicates that
+// the outbox relay publishes again within the duplicate window. Each consumer
+// group maps to one durable consumer per pattern.
+func NewJetStreamBus(ctx context.Context, nc *nats.Conn, stream string) (Bus, error) {
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:       stream,
+		Subjects:   []string{AllTopics()},
+		Duplicates: jetStreamDuplicateWindow,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stream %s: %w", stream, err)
+	}
+
+	return &jetStreamBus{nc: nc, js: js, stream: stream, logger: logs.New("jetstream_bus")}, nil
+}
+
+func (b *jetStreamBus) Publish(ctx context.Context, topic string, envelope *api.EventEnvelope) error {
+	data, err := proto.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event %s: %w", envelope.GetId(), err)
+	}
+
+	if _, err := b.js.Publish(ctx, topic, data, jetstream.WithMsgID(envelope.GetId())); err != nil {
+		return fmt.Errorf("failed to publish event %s: %w", envelope.GetId(), err)
+	}
+
+	return nil
+}
+
+func (b *jetStreamBus) Subscribe(ctx context.Context, pattern, group string, handler Handler) (Subscription, error) {
+	consumer, err := b.js.CreateOrUpdateConsumer(ctx, b.stream, jetstream.ConsumerConfig{
+		Durable:       durableReplacer.Replace(group + "_" + pattern),
+		FilterSubject: pattern,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       jetStreamAckWait,
+		MaxDeliver:    jetStreamMaxDeliver,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consumer for group %s: %w", group, err)
+	}
+
+	consumeContext, err := consumer.Consume(func(msg jetstream.Msg) {
+		var envelope api.EventEnvelope
+		if err := proto.Unmarshal(msg.Data(), &envelope); err != nil {
+			// A malformed message will never succeed, so stop redelivering it.
+			b.logger.Error("Dropping malformed message on %s: %v", msg.Subject(), err)
+			_ = msg.Term()
+			return
+		}
+
+		if err := handler(context.Background(), &envelope); err != nil {
+			b.logger.Warn("Handler failed for event %s: %v", envelope.GetId(), err)
+			_ = msg.Nak()
+			return
+		}
+
+		if err := msg.Ack(); err != nil {
+			b.logger.Warn("Failed to ack event %s: %v", envelope.GetId(), err)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume for group %s: %w", group, err)
+	}
+
+	return &jetStreamSubscription{consumeContext: consumeContext}, nil
+}
+
+func (b *jetStreamBus) Close() error {
+	return b.nc.Drain()
+}
+
+func (s *jetStreamSubscription) Unsubscribe() error {
+	s.consumeContext.Stop()
+	return nil
+}
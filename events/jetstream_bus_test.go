@@ -0,0 +1,94 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+// runNATSServer starts an embedded NATS server with JetStream enabled and
+// returns a connection to it.
+func runNATSServer(t *testing.T) *nats.Conn {
+	t.Helper()
+
+	ns, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create NATS server: %v", err)
+	}
+
+	go ns.Start()
+	t.Cleanup(ns.Shutdown)
+	if !ns.ReadyForConnections(10 * time.Second) {
+		t.Fatal("NATS server did not start")
+	}
+
+	nc, err := nats.Connect(ns.ClientURL())
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	return nc
+}
+
+func newTestJetStreamBus(t *testing.T) Bus {
+	t.Helper()
+
+	bus, err := NewJetStreamBus(context.Background(), runNATSServer(t), "EVENTS")
+	if err != nil {
+		t.Fatalf("NewJetStreamBus() = %v", err)
+	}
+	t.Cleanup(func() { _ = bus.Close() })
+	return bus
+}
+
+func TestJetStreamBus(t *testing.T) {
+	testBus(t, newTestJetStreamBus)
+}
+
+func TestJetStreamBusDropsDuplicates(t *testing.T) {
+	bus := newTestJetStreamBus(t)
+	r := newRecorder()
+	subscribe(t, bus, AllTopics(), "billing", r.handle)
+
+	// The relay publishes an event again after a crash; JetStream drops it
+	// by its ID.
+	publish(t, bus, Topic("order", "OrderCreated"), "e1", "e1", "e2")
+
+	if got := waitFor(t, 2, r); got[0] != "e1" || got[1] != "e2" {
+		t.Fatalf("received %v, want [e1 e2]", got)
+	}
+	assertQuiet(t, r)
+}
+
+func TestJetStreamBusDurableGroups(t *testing.T) {
+	bus := newTestJetStreamBus(t)
+	topic := Topic("order", "OrderCreated")
+
+	first := newRecorder()
+	sub := subscribe(t, bus, AllTopics(), "billing", first.handle)
+	publish(t, bus, topic, "e1")
+	waitFor(t, 1, first)
+	if err := sub.Unsubscribe(); err != nil {
+		t.Fatalf("Unsubscribe() = %v", err)
+	}
+
+	// Events published while a group has no subscriber wait for it, and
+	// events it already acknowledged are not delivered again.
+	publish(t, bus, topic, "e2")
+	second := newRecorder()
+	subscribe(t, bus, AllTopics(), "billing", second.handle)
+
+	if got := waitFor(t, 1, second); got[0] != "e2" {
+		t.Fatalf("received %s, want e2", got[0])
+	}
+	assertQuiet(t, second)
+}
@@ -0,0 +1,173 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	logs "github.com/daffaromero/gorpc-template/helper/logger"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+)
+
+const (
+	memoryBufferSize = 1024
+	memoryMaxDeliver = 5
+)
+
+type memoryBus struct {
+	mu     sync.Mutex
+	groups map[string]*memoryGroup
+	closed bool
+	wg     sync.WaitGroup
+	logger *logs.Log
+}
+
+type memoryGroup struct {
+	pattern string
+	members []*memorySubscription
+	next    int
+}
+
+type memorySubscription struct {
+	bus     *memoryBus
+	key     string
+	handler Handler
+	queue   chan *api.EventEnvelope
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NewMemoryBus returns an in-process Bus for tests and single binary
+// deployments. Events are not persisted: anything still queued when the
+// process stops is lost.
+func NewMemoryBus() Bus {
+	return &memoryBus{groups: make(map[string]*memoryGroup), logger: logs.New("memory_bus")}
+}
+
+func (b *memoryBus) Publish(ctx context.Context, topic string, envelope *api.EventEnvelope) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrClosed
+	}
+
+	var targets []*memorySubscription
+	for _, group := range b.groups {
+		if len(group.members) == 0 || !Match(group.pattern, topic) {
+			continue
+		}
+		targets = append(targets, group.members[group.next%len(group.members)])
+		group.next++
+	}
+	b.mu.Unlock()
+
+	for _, sub := range targets {
+		select {
+		case sub.queue <- envelope:
+		case <-sub.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+func (b *memoryBus) Subscribe(ctx context.Context, pattern, group string, handler Handler) (Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	key := group + "|" + pattern
+	g, ok := b.groups[key]
+	if !ok {
+		g = &memoryGroup{pattern: pattern}
+		b.groups[key] = g
+	}
+
+	sub := &memorySubscription{
+		bus:     b,
+		key:     key,
+		handler: handler,
+		queue:   make(chan *api.EventEnvelope, memoryBufferSize),
+		done:    make(chan struct{}),
+	}
+	g.members = append(g.members, sub)
+
+	b.wg.Add(1)
+	go sub.run()
+
+	return sub, nil
+}
+
+func (b *memoryBus) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+
+	var subs []*memorySubscription
+	for _, group := range b.groups {
+		subs = append(subs, group.members...)
+	}
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.stop()
+	}
+	b.wg.Wait()
+
+	return nil
+}
+
+func (s *memorySubscription) Unsubscribe() error {
+	s.bus.mu.Lock()
+	if group, ok := s.bus.groups[s.key]; ok {
+		for i, member := range group.members {
+			if member == s {
+				group.members = append(group.members[:i], group.members[i+1:]...)
+				break
+			}
+		}
+		if len(group.members) == 0 {
+			delete(s.bus.groups, s.key)
+		}
+	}
+	s.bus.mu.Unlock()
+
+	s.stop()
+	return nil
+}
+
+func (s *memorySubscription) stop() {
+	s.once.Do(func() { close(s.done) })
+}
+
+func (s *memorySubscription) run() {
+	defer s.bus.wg.Done()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case envelope := <-s.queue:
+			s.deliver(envelope)
+		}
+	}
+}
+
+func (s *memorySubscription) deliver(envelope *api.EventEnvelope) {
+	for attempt := 1; attempt <= memoryMaxDeliver; attempt++ {
+		err := s.handler(context.Background(), envelope)
+		if err == nil {
+			return
+		}
+		s.bus.logger.Warn("Handler failed for event %s (attempt %d/%d): %v", envelope.GetId(), attempt, memoryMaxDeliver, err)
+	}
+
+	s.bus.logger.Error("Dropping event %s after %d attempts", envelope.GetId(), memoryMaxDeliver)
+}
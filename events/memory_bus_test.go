@@ -0,0 +1,48 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMemoryBus(t *testing.T) {
+	testBus(t, func(t *testing.T) Bus {
+		bus := NewMemoryBus()
+		t.Cleanup(func() { _ = bus.Close() })
+		return bus
+	})
+}
+
+func TestMemoryBusRoundRobin(t *testing.T) {
+	bus := NewMemoryBus()
+	defer bus.Close()
+
+	first, second := newRecorder(), newRecorder()
+	subscribe(t, bus, AllTopics(), "billing", first.handle)
+	subscribe(t, bus, AllTopics(), "billing", second.handle)
+
+	publish(t, bus, Topic("order", "OrderCreated"), "e1", "e2", "e3", "e4")
+	waitFor(t, 4, first, second)
+
+	if first.count() != 2 || second.count() != 2 {
+		t.Fatalf("members handled %d and %d events, want 2 each", first.count(), second.count())
+	}
+}
+
+func TestMemoryBusClosed(t *testing.T) {
+	bus := NewMemoryBus()
+	if err := bus.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if err := bus.Close(); err != nil {
+		t.Fatalf("second Close() = %v", err)
+	}
+
+	if err := bus.Publish(context.Background(), Topic("order", "OrderCreated"), envelopeWithID("e1")); !errors.Is(err, ErrClosed) {
+		t.Errorf("Publish() after Close = %v, want ErrClosed", err)
+	}
+	if _, err := bus.Subscribe(context.Background(), AllTopics(), "billing", newRecorder().handle); !errors.Is(err, ErrClosed) {
+		t.Errorf("Subscribe() after Close = %v, want ErrClosed", err)
+	}
+}
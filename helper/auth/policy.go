@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUnauthenticated  = errors.New("authentication required")
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// OwnerFunc returns the ID of the user owning the resource addressed by req.
+// req is nil for streaming calls, where the request is not known up front.
+type OwnerFunc func(ctx context.Context, req any) (string, error)
+
+// Rule describes who may call a method. A public rule lets anyone in. Any
+// other rule requires an authenticated principal and, when Roles or Owner are
+// set, that the principal has one of the roles or owns the resource.
+type Rule struct {
+	Public bool
+	Roles  []string
+	Owner  OwnerFunc
+}
+
+// Policy maps full gRPC method names, such as "/UserService/DeleteUser", to
+// their rules. Methods without a rule are denied.
+type Policy map[string]Rule
+
+// Authorize decides whether principal may call method with req. It returns
+// ErrUnauthenticated or ErrPermissionDenied when the call is not allowed.
+func (p Policy) Authorize(ctx context.Context, method string, req any, principal *Principal) error {
+	rule, ok := p[method]
+	if !ok {
+		return fmt.Errorf("%w: no access rule for %s", ErrPermissionDenied, method)
+	}
+
+	if rule.Public {
+		return nil
+	}
+
+	if principal == nil {
+		return ErrUnauthenticated
+	}
+
+	if len(rule.Roles) == 0 && rule.Owner == nil {
+		return nil
+	}
+
+	for _, role := range rule.Roles {
+		if principal.Role == role {
+			return nil
+		}
+	}
+
+	if rule.Owner != nil && req != nil {
+		ownerID, err := rule.Owner(ctx, req)
+		if err != nil {
+			return err
+		}
+		if ownerID != "" && ownerID == principal.UserID {
+			return nil
+		}
+	}
+
+	return ErrPermissionDenied
+}
+
+// IsPublic reports whether method may be called without credentials.
+func (p Policy) IsPublic(method string) bool {
+	return p[method].Public
+}
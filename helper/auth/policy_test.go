@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPolicyAuthorize(t *testing.T) {
+	errLookup := errors.New("lookup failed")
+
+	ownedBy := func(ownerID string) OwnerFunc {
+		return func(ctx context.Context, req any) (string, error) {
+			return ownerID, nil
+		}
+	}
+
+	policy := Policy{
+		"/Test/Public":        Rule{Public: true},
+		"/Test/Authenticated": Rule{},
+		"/Test/AdminOnly":     Rule{Roles: []string{RoleAdmin}},
+		"/Test/Owned":         Rule{Roles: []string{RoleAdmin}, Owner: ownedBy("alice")},
+		"/Test/Unowned":       Rule{Roles: []string{RoleAdmin}, Owner: ownedBy("")},
+		"/Test/LookupFails": Rule{Roles: []string{RoleAdmin}, Owner: func(ctx context.Context, req any) (string, error) {
+			return "", errLookup
+		}},
+	}
+
+	alice := &Principal{UserID: "alice", Role: RoleUser}
+	bob := &Principal{UserID: "bob", Role: RoleUser}
+	admin := &Principal{UserID: "root", Role: RoleAdmin}
+	req := struct{}{}
+
+	tests := []struct {
+		name      string
+		method    string
+		req       any
+		principal *Principal
+		want      error
+	}{
+		{"public without principal", "/Test/Public", req, nil, nil},
+		{"authenticated without principal", "/Test/Authenticated", req, nil, ErrUnauthenticated},
+		{"authenticated with principal", "/Test/Authenticated", req, bob, nil},
+		{"admin only as user", "/Test/AdminOnly", req, alice, ErrPermissionDenied},
+		{"admin only as admin", "/Test/AdminOnly", req, admin, nil},
+		{"owner", "/Test/Owned", req, alice, nil},
+		{"non-owner", "/Test/Owned", req, bob, ErrPermissionDenied},
+		{"admin on owned resource", "/Test/Owned", req, admin, nil},
+		{"owner without request", "/Test/Owned", nil, alice, ErrPermissionDenied},
+		{"resource without owner", "/Test/Unowned", req, alice, ErrPermissionDenied},
+		{"owner lookup fails", "/Test/LookupFails", req, alice, errLookup},
+		{"admin skips owner lookup", "/Test/LookupFails", req, admin, nil},
+		{"unknown method", "/Test/Unknown", req, admin, ErrPermissionDenied},
+		{"unknown method without principal", "/Test/Unknown", req, nil, ErrPermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Authorize(context.Background(), tt.method, tt.req, tt.principal)
+			if tt.want == nil && err != nil {
+				t.Fatalf("Authorize() = %v, want nil", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("Authorize() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyIsPublic(t *testing.T) {
+	policy := Policy{"/Test/Public": Rule{Public: true}, "/Test/Private": Rule{}}
+
+	for method, want := range map[string]bool{"/Test/Public": true, "/Test/Private": false, "/Test/Unknown": false} {
+		if got := policy.IsPublic(method); got != want {
+			t.Errorf("IsPublic(%q) = %v, want %v", method, got, want)
+		}
+	}
+}
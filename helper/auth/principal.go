@@ -0,0 +1,31 @@
+package auth
+
+import "context"
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Principal is the authenticated caller of an RPC.
+type Principal struct {
+	UserID string
+	Role   string
+}
+
+func (p *Principal) IsAdmin() bool {
+	return p != nil && p.Role == RoleAdmin
+}
+
+type principalKey struct{}
+
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the principal stored by the auth interceptor, or nil
+// for unauthenticated calls to public methods.
+func FromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
@@ -0,0 +1,134 @@
+package domainerr
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Kind classifies an Error. Kinds are errors themselves so that callers can
+// test for them with errors.Is, e.g. errors.Is(err, domainerr.NotFound).
+type Kind string
+
+const (
+	NotFound           Kind = "not found"
+	AlreadyExists      Kind = "already exists"
+	InvalidArgument    Kind = "invalid argument"
+	FailedPrecondition Kind = "failed precondition"
+	Conflict           Kind = "conflict"
+	PermissionDenied   Kind = "permission denied"
+)
+
+func (k Kind) Error() string {
+	return string(k)
+}
+
+// Resource identifies the resource an Error is about.
+type Resource struct {
+	Type string
+	Name string
+}
+
+// FieldViolation describes a single invalid field of a request.
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// Info is a machine-readable reason for an Error, with facts a client needs
+// to recover from it.
+type Info struct {
+	Reason   string
+	Metadata map[string]string
+}
+
+// Error is an error the caller can act on. Message is safe to return to
+// clients; the wrapped cause is only meant for logs.
+type Error struct {
+	Kind       Kind
+	Message    string
+	Resource   *Resource
+	Violations []FieldViolation
+	Info       *Info
+	Err        error
+}
+
+func New(kind Kind, format string, args ...any) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+// ForResource returns an error of the given kind about a resource, with a
+// message such as "item 42 <outcome>".
+func ForResource(kind Kind, resourceType, name, outcome string) *Error {
+	return New(kind, "%s", describe(resourceType, name, outcome)).WithResource(resourceType, name)
+}
+
+// ResourceNotFound returns a NotFound error for the resource of the given
+// type, e.g. ResourceNotFound("item", id).
+func ResourceNotFound(resourceType, name string) *Error {
+	return ForResource(NotFound, resourceType, name, "not found")
+}
+
+// ResourceExists returns an AlreadyExists error for the resource of the given
+// type.
+func ResourceExists(resourceType, name string) *Error {
+	return ForResource(AlreadyExists, resourceType, name, "already exists")
+}
+
+// Invalid returns an InvalidArgument error for a single field.
+func Invalid(field, format string, args ...any) *Error {
+	description := fmt.Sprintf(format, args...)
+	return New(InvalidArgument, "%s: %s", field, description).WithViolations(FieldViolation{Field: field, Description: description})
+}
+
+// VersionMismatch returns a Conflict error for a conditional update of a
+// resource that has changed since the client read it. The current version is
+// reported in Info, so the client can read the resource again and retry.
+func VersionMismatch(resourceType, name string, current int64) *Error {
+	outcome := fmt.Sprintf("has been modified, current version is %d", current)
+	return ForResource(Conflict, resourceType, name, outcome).
+		WithInfo("VERSION_MISMATCH", map[string]string{"current_version": strconv.FormatInt(current, 10)})
+}
+
+func (e *Error) WithResource(resourceType, name string) *Error {
+	e.Resource = &Resource{Type: resourceType, Name: name}
+	return e
+}
+
+func (e *Error) WithViolations(violations ...FieldViolation) *Error {
+	e.Violations = append(e.Violations, violations...)
+	return e
+}
+
+func (e *Error) WithInfo(reason string, metadata map[string]string) *Error {
+	e.Info = &Info{Reason: reason, Metadata: metadata}
+	return e
+}
+
+func (e *Error) WithCause(err error) *Error {
+	e.Err = err
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the kind of e.
+func (e *Error) Is(target error) bool {
+	kind, ok := target.(Kind)
+	return ok && kind == e.Kind
+}
+
+func describe(resourceType, name, outcome string) string {
+	if name == "" {
+		return resourceType + " " + outcome
+	}
+	return resourceType + " " + name + " " + outcome
+}
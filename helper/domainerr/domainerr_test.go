@@ -0,0 +1,73 @@
+package domainerr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestKindMatching(t *testing.T) {
+	err := fmt.Errorf("failed to get item: %w", ResourceNotFound("item", "42"))
+
+	if !errors.Is(err, NotFound) {
+		t.Errorf("errors.Is(%v, NotFound) = false", err)
+	}
+	if errors.Is(err, AlreadyExists) {
+		t.Errorf("errors.Is(%v, AlreadyExists) = true", err)
+	}
+
+	var domainErr *Error
+	if !errors.As(err, &domainErr) || domainErr.Resource == nil || domainErr.Resource.Type != "item" || domainErr.Resource.Name != "42" {
+		t.Errorf("errors.As(%v) = %+v", err, domainErr)
+	}
+}
+
+func TestMessages(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *Error
+		want string
+	}{
+		{"not found", ResourceNotFound("item", "42"), "item 42 not found"},
+		{"unnamed resource", ResourceNotFound("refresh token", ""), "refresh token not found"},
+		{"exists", ResourceExists("user", "a@example.com"), "user a@example.com already exists"},
+		{"invalid", Invalid("page_size", "must be at most %d", 100), "page_size: must be at most 100"},
+		{"version mismatch", VersionMismatch("seller", "7", 3), "seller 7 has been modified, current version is 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err.Message != tt.want {
+				t.Errorf("Message = %q, want %q", tt.err.Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestCause(t *testing.T) {
+	cause := errors.New("duplicate key value violates unique constraint")
+	err := ResourceExists("user", "a@example.com").WithCause(cause)
+
+	if !errors.Is(err, cause) {
+		t.Error("the cause is not unwrapped")
+	}
+	if got, want := err.Error(), "user a@example.com already exists: "+cause.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	// The message returned to clients leaves the cause out.
+	if err.Message != "user a@example.com already exists" {
+		t.Errorf("Message = %q", err.Message)
+	}
+}
+
+func TestDetails(t *testing.T) {
+	invalid := Invalid("user.email", "must be an email address")
+	if len(invalid.Violations) != 1 || invalid.Violations[0] != (FieldViolation{Field: "user.email", Description: "must be an email address"}) {
+		t.Errorf("Violations = %+v", invalid.Violations)
+	}
+
+	mismatch := VersionMismatch("item", "42", 5)
+	if !errors.Is(mismatch, Conflict) || mismatch.Info == nil || mismatch.Info.Reason != "VERSION_MISMATCH" || mismatch.Info.Metadata["current_version"] != "5" {
+		t.Errorf("VersionMismatch() = %+v", mismatch)
+	}
+}
@@ -0,0 +1,91 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+)
+
+var (
+	ErrMissingAmount    = errors.New("amount is required")
+	ErrCurrencyMismatch = errors.New("currencies do not match")
+	ErrInvalidCurrency  = errors.New("currency code must be three upper case letters")
+	ErrNegativeAmount   = errors.New("amount must not be negative")
+	ErrOverflow         = errors.New("amount overflows")
+)
+
+func New(currencyCode string, minorUnits int64) *api.Money {
+	return &api.Money{CurrencyCode: currencyCode, MinorUnits: minorUnits}
+}
+
+// Validate checks that m is a non-negative amount in a well formed ISO 4217
+// currency code. It does not check that the currency exists.
+func Validate(m *api.Money) error {
+	if m == nil {
+		return ErrMissingAmount
+	}
+
+	code := m.GetCurrencyCode()
+	if len(code) != 3 {
+		return fmt.Errorf("%q: %w", code, ErrInvalidCurrency)
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return fmt.Errorf("%q: %w", code, ErrInvalidCurrency)
+		}
+	}
+
+	if m.GetMinorUnits() < 0 {
+		return ErrNegativeAmount
+	}
+
+	return nil
+}
+
+// Add returns a + b. Both amounts must be in the same currency.
+func Add(a, b *api.Money) (*api.Money, error) {
+	if a.GetCurrencyCode() != b.GetCurrencyCode() {
+		return nil, fmt.Errorf("cannot add %s to %s: %w", b.GetCurrencyCode(), a.GetCurrencyCode(), ErrCurrencyMismatch)
+	}
+
+	x, y := a.GetMinorUnits(), b.GetMinorUnits()
+	if (y > 0 && x > math.MaxInt64-y) || (y < 0 && x < math.MinInt64-y) {
+		return nil, ErrOverflow
+	}
+
+	return New(a.GetCurrencyCode(), x+y), nil
+}
+
+// Multiply returns m times n.
+func Multiply(m *api.Money, n int64) (*api.Money, error) {
+	x := m.GetMinorUnits()
+	if x != 0 && n != 0 {
+		product := x * n
+		if product/n != x || (x == -1 && n == math.MinInt64) || (n == -1 && x == math.MinInt64) {
+			return nil, ErrOverflow
+		}
+		return New(m.GetCurrencyCode(), product), nil
+	}
+
+	return New(m.GetCurrencyCode(), 0), nil
+}
+
+// Sum adds up amounts, which must all share one currency. It returns an
+// error for an empty list since the currency of the result is unknown.
+func Sum(amounts ...*api.Money) (*api.Money, error) {
+	if len(amounts) == 0 {
+		return nil, errors.New("cannot sum an empty list of amounts")
+	}
+
+	total := New(amounts[0].GetCurrencyCode(), 0)
+	for _, amount := range amounts {
+		var err error
+		if total, err = Add(total, amount); err != nil {
+			return nil, err
+		}
+	}
+
+	return total, nil
+}
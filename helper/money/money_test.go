@@ -0,0 +1,120 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		money *api.Money
+		want  error
+	}{
+		{"valid", New("IDR", 150000), nil},
+		{"zero", New("USD", 0), nil},
+		{"missing", nil, ErrMissingAmount},
+		{"empty currency", New("", 100), ErrInvalidCurrency},
+		{"lower case currency", New("usd", 100), ErrInvalidCurrency},
+		{"long currency", New("USDT", 100), ErrInvalidCurrency},
+		{"non-ASCII currency", New("ÉUR", 100), ErrInvalidCurrency},
+		{"negative", New("EUR", -1), ErrNegativeAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.money)
+			if tt.want == nil && err != nil {
+				t.Fatalf("Validate() = %v, want nil", err)
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    *api.Money
+		want    *api.Money
+		wantErr error
+	}{
+		{"same currency", New("USD", 250), New("USD", 199), New("USD", 449), nil},
+		{"negative", New("USD", 250), New("USD", -300), New("USD", -50), nil},
+		{"currency mismatch", New("USD", 1), New("EUR", 1), nil, ErrCurrencyMismatch},
+		{"overflow", New("USD", math.MaxInt64), New("USD", 1), nil, ErrOverflow},
+		{"underflow", New("USD", math.MinInt64), New("USD", -1), nil, ErrOverflow},
+		{"largest sum", New("USD", math.MaxInt64-1), New("USD", 1), New("USD", math.MaxInt64), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Add(tt.a, tt.b)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Add() error = %v, want %v", err, tt.wantErr)
+			}
+			if !sameMoney(got, tt.want) {
+				t.Errorf("Add() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiply(t *testing.T) {
+	tests := []struct {
+		name    string
+		money   *api.Money
+		n       int64
+		want    *api.Money
+		wantErr error
+	}{
+		{"quantity", New("IDR", 15000), 3, New("IDR", 45000), nil},
+		{"zero quantity", New("IDR", 15000), 0, New("IDR", 0), nil},
+		{"zero amount", New("IDR", 0), math.MaxInt64, New("IDR", 0), nil},
+		{"overflow", New("IDR", math.MaxInt64/2+1), 2, nil, ErrOverflow},
+		{"min times minus one", New("IDR", math.MinInt64), -1, nil, ErrOverflow},
+		{"minus one times min", New("IDR", -1), math.MinInt64, nil, ErrOverflow},
+		{"largest product", New("IDR", math.MaxInt64/2), 2, New("IDR", math.MaxInt64-1), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Multiply(tt.money, tt.n)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Multiply() error = %v, want %v", err, tt.wantErr)
+			}
+			if !sameMoney(got, tt.want) {
+				t.Errorf("Multiply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSum(t *testing.T) {
+	got, err := Sum(New("EUR", 100), New("EUR", 250), New("EUR", 5))
+	if err != nil || !sameMoney(got, New("EUR", 355)) {
+		t.Errorf("Sum() = %v, %v, want EUR 355", got, err)
+	}
+
+	if _, err := Sum(New("EUR", 100), New("USD", 100)); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sum() of mixed currencies = %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := Sum(New("EUR", math.MaxInt64), New("EUR", 1)); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Sum() past MaxInt64 = %v, want ErrOverflow", err)
+	}
+	if _, err := Sum(); err == nil {
+		t.Error("Sum() of nothing did not fail")
+	}
+}
+
+func sameMoney(a, b *api.Money) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.GetCurrencyCode() == b.GetCurrencyCode() && a.GetMinorUnits() == b.GetMinorUnits()
+}
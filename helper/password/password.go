@@ -0,0 +1,59 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrMismatch        = errors.New("password does not match")
+	ErrPasswordTooLong = errors.New("password is longer than 72 bytes")
+)
+
+// Hasher hashes passwords for storage and verifies plain text passwords
+// against stored hashes.
+type Hasher interface {
+	Hash(password string) (string, error)
+	Verify(hash, password string) error
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher returns a Hasher using bcrypt with the given cost factor.
+func NewBcryptHasher(cost int) (Hasher, error) {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, cost)
+	}
+
+	return &bcryptHasher{cost: cost}, nil
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	// bcrypt silently ignores anything past 72 bytes, so reject it instead.
+	if len(password) > 72 {
+		return "", ErrPasswordTooLong
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return string(hash), nil
+}
+
+func (h *bcryptHasher) Verify(hash, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrMismatch
+		}
+		return fmt.Errorf("failed to verify password: %w", err)
+	}
+
+	return nil
+}
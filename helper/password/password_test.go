@@ -0,0 +1,93 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestHasher(t *testing.T) Hasher {
+	t.Helper()
+
+	hasher, err := NewBcryptHasher(bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("NewBcryptHasher() = %v", err)
+	}
+	return hasher
+}
+
+func TestNewBcryptHasherCost(t *testing.T) {
+	tests := []struct {
+		cost    int
+		wantErr bool
+	}{
+		{bcrypt.MinCost - 1, true},
+		{bcrypt.MinCost, false},
+		{bcrypt.DefaultCost, false},
+		{bcrypt.MaxCost, false},
+		{bcrypt.MaxCost + 1, true},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewBcryptHasher(tt.cost); (err != nil) != tt.wantErr {
+			t.Errorf("NewBcryptHasher(%d) = %v, want error %v", tt.cost, err, tt.wantErr)
+		}
+	}
+}
+
+func TestHashAndVerify(t *testing.T) {
+	hasher := newTestHasher(t)
+
+	hash, err := hasher.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Hash() = %v", err)
+	}
+	if hash == "correct horse battery staple" {
+		t.Fatal("Hash() returned the plain text password")
+	}
+
+	if err := hasher.Verify(hash, "correct horse battery staple"); err != nil {
+		t.Errorf("Verify() with the right password = %v", err)
+	}
+	if err := hasher.Verify(hash, "Correct horse battery staple"); !errors.Is(err, ErrMismatch) {
+		t.Errorf("Verify() with the wrong password = %v, want ErrMismatch", err)
+	}
+}
+
+func TestHashIsSalted(t *testing.T) {
+	hasher := newTestHasher(t)
+
+	first, err := hasher.Hash("secret")
+	if err != nil {
+		t.Fatalf("Hash() = %v", err)
+	}
+	second, err := hasher.Hash("secret")
+	if err != nil {
+		t.Fatalf("Hash() = %v", err)
+	}
+
+	if first == second {
+		t.Error("hashing the same password twice gave the same hash")
+	}
+}
+
+func TestHashTooLong(t *testing.T) {
+	hasher := newTestHasher(t)
+
+	if _, err := hasher.Hash(strings.Repeat("a", 72)); err != nil {
+		t.Errorf("Hash() of 72 bytes = %v", err)
+	}
+	if _, err := hasher.Hash(strings.Repeat("a", 73)); !errors.Is(err, ErrPasswordTooLong) {
+		t.Errorf("Hash() of 73 bytes = %v, want ErrPasswordTooLong", err)
+	}
+}
+
+func TestVerifyMalformedHash(t *testing.T) {
+	err := newTestHasher(t).Verify("not a bcrypt hash", "secret")
+
+	if err == nil || errors.Is(err, ErrMismatch) {
+		t.Errorf("Verify() with a malformed hash = %v, want an error other than ErrMismatch", err)
+	}
+}
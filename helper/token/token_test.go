@@ -0,0 +1,148 @@
+package token
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var testConfig = Config{
+	Issuer:        "gorpc-template",
+	AccessSecret:  []byte("access-secret"),
+	RefreshSecret: []byte("refresh-secret"),
+	AccessTTL:     15 * time.Minute,
+	RefreshTTL:    24 * time.Hour,
+}
+
+// newTestManager returns a manager whose clock is read from now.
+func newTestManager(t *testing.T, config Config, now *time.Time) *manager {
+	t.Helper()
+
+	m, err := NewManager(config)
+	if err != nil {
+		t.Fatalf("NewManager() = %v", err)
+	}
+	mgr := m.(*manager)
+	mgr.now = func() time.Time { return *now }
+	return mgr
+}
+
+func TestNewManagerRequiresSecrets(t *testing.T) {
+	for _, config := range []Config{
+		{RefreshSecret: []byte("refresh")},
+		{AccessSecret: []byte("access")},
+	} {
+		if _, err := NewManager(config); err == nil {
+			t.Errorf("NewManager(%+v) did not fail", config)
+		}
+	}
+}
+
+func TestAccessToken(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	m := newTestManager(t, testConfig, &now)
+
+	signed, expiresAt, err := m.IssueAccessToken("user-1", "admin")
+	if err != nil {
+		t.Fatalf("IssueAccessToken() = %v", err)
+	}
+	if want := now.Add(testConfig.AccessTTL); !expiresAt.Equal(want) {
+		t.Errorf("expiresAt = %v, want %v", expiresAt, want)
+	}
+
+	claims, err := m.ParseAccessToken(signed)
+	if err != nil {
+		t.Fatalf("ParseAccessToken() = %v", err)
+	}
+	if claims.Subject != "user-1" || claims.Role != "admin" || claims.Type != AccessToken || claims.Issuer != testConfig.Issuer {
+		t.Errorf("claims = %+v", claims)
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	m := newTestManager(t, testConfig, &now)
+
+	signed, _, err := m.IssueRefreshToken("user-1", "token-1")
+	if err != nil {
+		t.Fatalf("IssueRefreshToken() = %v", err)
+	}
+
+	claims, err := m.ParseRefreshToken(signed)
+	if err != nil {
+		t.Fatalf("ParseRefreshToken() = %v", err)
+	}
+	if claims.Subject != "user-1" || claims.ID != "token-1" || claims.Role != "" || claims.Type != RefreshToken {
+		t.Errorf("claims = %+v", claims)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	m := newTestManager(t, testConfig, &now)
+
+	access, _, err := m.IssueAccessToken("user-1", "user")
+	if err != nil {
+		t.Fatalf("IssueAccessToken() = %v", err)
+	}
+	refresh, _, err := m.IssueRefreshToken("user-1", "token-1")
+	if err != nil {
+		t.Fatalf("IssueRefreshToken() = %v", err)
+	}
+
+	sameSecret := testConfig
+	sameSecret.RefreshSecret = sameSecret.AccessSecret
+	shared := newTestManager(t, sameSecret, &now)
+	sharedRefresh, _, err := shared.IssueRefreshToken("user-1", "token-1")
+	if err != nil {
+		t.Fatalf("IssueRefreshToken() = %v", err)
+	}
+
+	otherIssuer := testConfig
+	otherIssuer.Issuer = "someone-else"
+	foreign, _, err := newTestManager(t, otherIssuer, &now).IssueAccessToken("user-1", "user")
+	if err != nil {
+		t.Fatalf("IssueAccessToken() = %v", err)
+	}
+
+	noSubject, _, err := m.IssueAccessToken("", "user")
+	if err != nil {
+		t.Fatalf("IssueAccessToken() = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		parse func(string) (*Claims, error)
+		token string
+	}{
+		{"garbage", m.ParseAccessToken, "not.a.token"},
+		{"refresh token as access token", m.ParseAccessToken, refresh},
+		{"access token as refresh token", m.ParseRefreshToken, access},
+		{"refresh token as access token with shared secret", shared.ParseAccessToken, sharedRefresh},
+		{"other issuer", m.ParseAccessToken, foreign},
+		{"no subject", m.ParseAccessToken, noSubject},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if claims, err := tt.parse(tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("parse() = %+v, %v, want ErrInvalidToken", claims, err)
+			}
+		})
+	}
+}
+
+func TestParseExpired(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	m := newTestManager(t, testConfig, &now)
+
+	signed, _, err := m.IssueAccessToken("user-1", "user")
+	if err != nil {
+		t.Fatalf("IssueAccessToken() = %v", err)
+	}
+
+	now = now.Add(testConfig.AccessTTL + time.Second)
+	if _, err := m.ParseAccessToken(signed); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("ParseAccessToken() of an expired token = %v, want ErrInvalidToken", err)
+	}
+}
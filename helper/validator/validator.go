@@ -0,0 +1,176 @@
+package validator
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+)
+
+// patterns caches compiled FieldRules.pattern expressions by source.
+var patterns sync.Map
+
+// Validate checks msg against the FieldRules declared on its fields, and on
+// the fields of the messages it embeds. It returns nil or an InvalidArgument
+// domain error listing every violation.
+func Validate(msg proto.Message) error {
+	var violations []domainerr.FieldViolation
+	validateMessage(msg.ProtoReflect(), "", &violations)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	first := violations[0]
+	err := domainerr.New(domainerr.InvalidArgument, "%s: %s", first.Field, first.Description)
+	if len(violations) > 1 {
+		err.Message = fmt.Sprintf("%s (and %d more)", err.Message, len(violations)-1)
+	}
+	return err.WithViolations(violations...)
+}
+
+func validateMessage(m protoreflect.Message, prefix string, violations *[]domainerr.FieldViolation) {
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		rules := fieldRules(fd)
+		path := prefix + string(fd.Name())
+
+		violate := func(format string, args ...any) {
+			*violations = append(*violations, domainerr.FieldViolation{Field: path, Description: fmt.Sprintf(format, args...)})
+		}
+
+		switch {
+		case fd.IsMap():
+			continue
+		case fd.IsList():
+			list := m.Get(fd).List()
+			if rules.GetRequired() && list.Len() == 0 {
+				violate("must not be empty")
+			}
+			if fd.Message() != nil && !rules.GetSkip() {
+				for j := 0; j < list.Len(); j++ {
+					validateMessage(list.Get(j).Message(), fmt.Sprintf("%s[%d].", path, j), violations)
+				}
+			}
+		case fd.Message() != nil:
+			if !m.Has(fd) {
+				if rules.GetRequired() {
+					violate("is required")
+				}
+				continue
+			}
+
+			nested := m.Get(fd).Message()
+			for _, name := range rules.GetRequiredFields() {
+				nestedField := nested.Descriptor().Fields().ByName(protoreflect.Name(name))
+				if nestedField == nil || !nested.Has(nestedField) {
+					*violations = append(*violations, domainerr.FieldViolation{Field: path + "." + name, Description: "is required"})
+				}
+			}
+			if !rules.GetSkip() {
+				validateMessage(nested, path+".", violations)
+			}
+		default:
+			if description := checkScalar(fd, m.Get(fd), rules); description != "" {
+				violate("%s", description)
+			}
+		}
+	}
+}
+
+// checkScalar returns why value breaks rules, or "" if it does not.
+func checkScalar(fd protoreflect.FieldDescriptor, value protoreflect.Value, rules *api.FieldRules) string {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return checkString(value.String(), rules)
+	case protoreflect.EnumKind:
+		if rules.GetRequired() && value.Enum() == 0 {
+			return "is required"
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n := value.Int()
+		switch {
+		case rules.GetRequired() && n == 0:
+			return "must not be zero"
+		case rules != nil && rules.Gte != nil && n < rules.GetGte():
+			return fmt.Sprintf("must be at least %d", rules.GetGte())
+		case rules != nil && rules.Lte != nil && n > rules.GetLte():
+			return fmt.Sprintf("must be at most %d", rules.GetLte())
+		}
+	}
+
+	return ""
+}
+
+func checkString(s string, rules *api.FieldRules) string {
+	if s == "" {
+		if rules.GetRequired() {
+			return "is required"
+		}
+		return ""
+	}
+
+	length := uint32(utf8.RuneCountInString(s))
+	switch {
+	case rules.GetMinLen() > 0 && length < rules.GetMinLen():
+		return fmt.Sprintf("must be at least %d characters long", rules.GetMinLen())
+	case rules.GetMaxLen() > 0 && length > rules.GetMaxLen():
+		return fmt.Sprintf("must be at most %d characters long", rules.GetMaxLen())
+	case rules.GetMaxBytes() > 0 && uint32(len(s)) > rules.GetMaxBytes():
+		return fmt.Sprintf("must be at most %d bytes long", rules.GetMaxBytes())
+	case rules.GetUuid() && !isUUID(s):
+		return "must be a UUID"
+	case rules.GetEmail() && !isEmail(s):
+		return "must be an email address"
+	case rules.GetPattern() != "" && !pattern(rules.GetPattern()).MatchString(s):
+		return fmt.Sprintf("must match %q", rules.GetPattern())
+	}
+
+	return ""
+}
+
+// fieldRules returns the rules declared on fd, or nil. The getters of
+// api.FieldRules treat nil as "no rules".
+func fieldRules(fd protoreflect.FieldDescriptor) *api.FieldRules {
+	options, ok := fd.Options().(*descriptorpb.FieldOptions)
+	if !ok || options == nil {
+		return nil
+	}
+
+	rules, _ := proto.GetExtension(options, api.E_Rules).(*api.FieldRules)
+	return rules
+}
+
+// isUUID only accepts the canonical 36 character form that Postgres returns.
+func isUUID(s string) bool {
+	_, err := uuid.Parse(s)
+	return err == nil && len(s) == 36
+}
+
+// isEmail accepts a bare address such as "ann@example.com", without a display
+// name or angle brackets.
+func isEmail(s string) bool {
+	address, err := mail.ParseAddress(s)
+	return err == nil && address.Address == s
+}
+
+func pattern(source string) *regexp.Regexp {
+	if re, ok := patterns.Load(source); ok {
+		return re.(*regexp.Regexp)
+	}
+
+	// Patterns come from api.proto, so a bad one is a programming error.
+	re := regexp.MustCompile(`^(?:` + source + `)$`)
+	patterns.Store(source, re)
+	return re
+}
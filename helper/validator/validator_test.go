@@ -0,0 +1,112 @@
+package validator
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+)
+
+const (
+	itemID = "0191c5f2-7c1e-7d3a-9f00-000000000001"
+	userID = "0191c5f2-7c1e-7d3a-9f00-000000000002"
+)
+
+func validItem() *api.Item {
+	return &api.Item{Name: "Widget", Price: &api.Money{CurrencyCode: "IDR", MinorUnits: 15000}, Stock: 3}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  proto.Message
+		want []string
+	}{
+		{"valid item", &api.CreateItemRequest{Item: validItem()}, nil},
+		{"missing item", &api.CreateItemRequest{}, []string{"item"}},
+		{"item without name and price", &api.CreateItemRequest{Item: &api.Item{}}, []string{"item.name", "item.price"}},
+		{"name too long", &api.CreateItemRequest{Item: func() *api.Item {
+			item := validItem()
+			item.Name = strings.Repeat("é", 256)
+			return item
+		}()}, []string{"item.name"}},
+		{"name at max length", &api.CreateItemRequest{Item: func() *api.Item {
+			item := validItem()
+			item.Name = strings.Repeat("é", 255)
+			return item
+		}()}, nil},
+		{"bad currency and negative stock", &api.CreateItemRequest{Item: func() *api.Item {
+			item := validItem()
+			item.Price.CurrencyCode = "idr"
+			item.Stock = -1
+			return item
+		}()}, []string{"item.price.currency_code", "item.stock"}},
+		{"seller id not a uuid", &api.CreateItemRequest{Item: func() *api.Item {
+			item := validItem()
+			item.SellerId = "seller-1"
+			return item
+		}()}, []string{"item.seller_id"}},
+		{"update without id and version", &api.UpdateItemRequest{Item: validItem()}, []string{"item.id", "item.version"}},
+		{"update with id and version", &api.UpdateItemRequest{Item: func() *api.Item {
+			item := validItem()
+			item.Id, item.Version = itemID, 2
+			return item
+		}()}, nil},
+		{"uuid without dashes", &api.GetItemRequest{Id: strings.ReplaceAll(itemID, "-", "")}, []string{"id"}},
+		{"page in range", &api.ListItemsRequest{Page: 1000000}, nil},
+		{"page too large", &api.ListItemsRequest{Page: 1000001}, []string{"page"}},
+		{"negative page", &api.ListUsersRequest{Page: -1}, []string{"page"}},
+		{"zero delta", &api.AdjustStockRequest{ItemId: itemID}, []string{"delta"}},
+		{"bad email", &api.CreateUserRequest{User: &api.User{Name: "Ann", Email: "Ann <ann@example.com>", Password: "secret"}}, []string{"user.email"}},
+		{"missing password", &api.CreateUserRequest{User: &api.User{Name: "Ann", Email: "ann@example.com"}}, []string{"user.password"}},
+		{"password too long", &api.CreateUserRequest{User: &api.User{Name: "Ann", Email: "ann@example.com", Password: strings.Repeat("a", 73)}}, []string{"user.password"}},
+		{"order without items", &api.CreateOrderRequest{Order: &api.Order{UserId: userID}}, []string{"order.items"}},
+		{"order line", &api.CreateOrderRequest{Order: &api.Order{UserId: userID, Items: []*api.OrderItem{
+			{Item: &api.Item{Id: itemID}, Quantity: 1},
+			{Item: &api.Item{}, Quantity: 0},
+		}}}, []string{"order.items[1].item.id", "order.items[1].quantity"}},
+		{"unknown payment status", &api.ConfirmPaymentRequest{OrderId: itemID, PaymentToken: "tok"}, []string{"status"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.msg)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+
+			var domainErr *domainerr.Error
+			if !errors.As(err, &domainErr) || !errors.Is(err, domainerr.InvalidArgument) {
+				t.Fatalf("Validate() = %v, want an InvalidArgument domain error", err)
+			}
+
+			var fields []string
+			for _, violation := range domainErr.Violations {
+				fields = append(fields, violation.Field)
+			}
+			if !slices.Equal(fields, tt.want) {
+				t.Errorf("violations = %q, want %q", fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateMessage(t *testing.T) {
+	err := Validate(&api.CreateItemRequest{Item: &api.Item{}})
+
+	var domainErr *domainerr.Error
+	if !errors.As(err, &domainErr) {
+		t.Fatalf("Validate() = %v", err)
+	}
+	if want := "item.name: is required (and 1 more)"; domainErr.Message != want {
+		t.Errorf("Message = %q, want %q", domainErr.Message, want)
+	}
+}
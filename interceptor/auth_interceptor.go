@@ -0,0 +1,102 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/daffaromero/gorpc-template/helper/auth"
+	"github.com/daffaromero/gorpc-template/helper/token"
+)
+
+type authInterceptor struct {
+	tokenManager token.Manager
+	policy       auth.Policy
+}
+
+// NewAuthInterceptors returns unary and stream interceptors that validate the
+// bearer token in the "authorization" metadata, store the principal in the
+// context and enforce policy.
+func NewAuthInterceptors(tokenManager token.Manager, policy auth.Policy) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	i := &authInterceptor{tokenManager: tokenManager, policy: policy}
+	return i.unary, i.stream
+}
+
+func (i *authInterceptor) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := i.authorize(ctx, info.FullMethod, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (i *authInterceptor) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := i.authorize(ss.Context(), info.FullMethod, nil)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+func (i *authInterceptor) authorize(ctx context.Context, method string, req any) (context.Context, error) {
+	principal, err := i.principal(ctx)
+	if err != nil && !i.policy.IsPublic(method) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if principal != nil {
+		ctx = auth.NewContext(ctx, principal)
+	}
+
+	if err := i.policy.Authorize(ctx, method, req, principal); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrUnauthenticated):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		case errors.Is(err, auth.ErrPermissionDenied):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+
+		// The owner lookup itself failed, e.g. the resource does not exist.
+		// The error interceptor turns this into a status.
+		return nil, err
+	}
+
+	return ctx, nil
+}
+
+// principal returns nil without an error when no credentials were sent.
+func (i *authInterceptor) principal(ctx context.Context) (*auth.Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	scheme, bearer, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") {
+		return nil, errors.New("authorization header must use the Bearer scheme")
+	}
+
+	claims, err := i.tokenManager.ParseAccessToken(bearer)
+	if err != nil {
+		return nil, errors.New("invalid access token")
+	}
+
+	return &auth.Principal{UserID: claims.Subject, Role: claims.Role}, nil
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
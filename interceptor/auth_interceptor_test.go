@@ -0,0 +1,110 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/daffaromero/gorpc-template/helper/auth"
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/helper/token"
+)
+
+func TestAuthUnary(t *testing.T) {
+	manager, err := token.NewManager(token.Config{
+		Issuer:        "test",
+		AccessSecret:  []byte("access"),
+		RefreshSecret: []byte("refresh"),
+		AccessTTL:     time.Minute,
+		RefreshTTL:    time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewManager() = %v", err)
+	}
+
+	userToken, _, err := manager.IssueAccessToken("alice", auth.RoleUser)
+	if err != nil {
+		t.Fatalf("IssueAccessToken() = %v", err)
+	}
+	refreshToken, _, err := manager.IssueRefreshToken("alice", "token-1")
+	if err != nil {
+		t.Fatalf("IssueRefreshToken() = %v", err)
+	}
+
+	policy := auth.Policy{
+		"/Test/Public":    auth.Rule{Public: true},
+		"/Test/Private":   auth.Rule{},
+		"/Test/AdminOnly": auth.Rule{Roles: []string{auth.RoleAdmin}},
+		"/Test/Missing": auth.Rule{Roles: []string{auth.RoleAdmin}, Owner: func(ctx context.Context, req any) (string, error) {
+			return "", domainerr.ResourceNotFound("item", "item-1")
+		}},
+	}
+	unary, _ := NewAuthInterceptors(manager, policy)
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		wantCode      codes.Code
+		wantPrincipal string
+	}{
+		{"public without credentials", "/Test/Public", "", codes.OK, ""},
+		{"public with credentials", "/Test/Public", "Bearer " + userToken, codes.OK, "alice"},
+		{"public with a bad token", "/Test/Public", "Bearer nonsense", codes.OK, ""},
+		{"private without credentials", "/Test/Private", "", codes.Unauthenticated, ""},
+		{"private with credentials", "/Test/Private", "Bearer " + userToken, codes.OK, "alice"},
+		{"lower case scheme", "/Test/Private", "bearer " + userToken, codes.OK, "alice"},
+		{"basic scheme", "/Test/Private", "Basic " + userToken, codes.Unauthenticated, ""},
+		{"refresh token", "/Test/Private", "Bearer " + refreshToken, codes.Unauthenticated, ""},
+		{"wrong role", "/Test/AdminOnly", "Bearer " + userToken, codes.PermissionDenied, ""},
+		{"unknown method", "/Test/Unknown", "Bearer " + userToken, codes.PermissionDenied, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.authorization))
+			}
+
+			var principal *auth.Principal
+			_, err := unary(ctx, struct{}{}, &grpc.UnaryServerInfo{FullMethod: tt.method}, func(ctx context.Context, req any) (any, error) {
+				principal = auth.FromContext(ctx)
+				return nil, nil
+			})
+
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %v, want %v (err = %v)", code, tt.wantCode, err)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+			var userID string
+			if principal != nil {
+				userID = principal.UserID
+			}
+			if userID != tt.wantPrincipal {
+				t.Errorf("principal = %q, want %q", userID, tt.wantPrincipal)
+			}
+		})
+	}
+
+	t.Run("owner lookup fails", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+userToken))
+
+		_, err := unary(ctx, struct{}{}, &grpc.UnaryServerInfo{FullMethod: "/Test/Missing"}, func(ctx context.Context, req any) (any, error) {
+			t.Error("handler called")
+			return nil, nil
+		})
+
+		if !errors.Is(err, domainerr.NotFound) {
+			t.Errorf("err = %v, want the NotFound from the lookup for the error interceptor", err)
+		}
+	})
+}
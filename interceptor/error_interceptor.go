@@ -0,0 +1,123 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	logs "github.com/daffaromero/gorpc-template/helper/logger"
+)
+
+// errorInfoDomain is the domain of the ErrorInfo details sent to clients.
+const errorInfoDomain = "gorpc-template"
+
+var kindCodes = map[domainerr.Kind]codes.Code{
+	domainerr.NotFound:           codes.NotFound,
+	domainerr.AlreadyExists:      codes.AlreadyExists,
+	domainerr.InvalidArgument:    codes.InvalidArgument,
+	domainerr.FailedPrecondition: codes.FailedPrecondition,
+	domainerr.Conflict:           codes.Aborted,
+	domainerr.PermissionDenied:   codes.PermissionDenied,
+}
+
+type errorInterceptor struct {
+	logger *logs.Log
+}
+
+// NewErrorInterceptors returns unary and stream interceptors that turn errors
+// returned by handlers into gRPC statuses. Domain errors keep their message
+// and get ResourceInfo, BadRequest and ErrorInfo details; status errors pass
+// through unchanged; anything else is logged and reported as codes.Internal
+// so that internals never reach the client. They should run before all other
+// interceptors.
+func NewErrorInterceptors() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	i := &errorInterceptor{logger: logs.New("error_interceptor")}
+	return i.unary, i.stream
+}
+
+func (i *errorInterceptor) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, i.toStatusError(info.FullMethod, err)
+	}
+
+	return resp, nil
+}
+
+func (i *errorInterceptor) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := handler(srv, ss); err != nil {
+		return i.toStatusError(info.FullMethod, err)
+	}
+
+	return nil
+}
+
+func (i *errorInterceptor) toStatusError(method string, err error) error {
+	var domainErr *domainerr.Error
+	if errors.As(err, &domainErr) {
+		return domainStatus(domainErr).Err()
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	i.logger.Error("Unexpected error in %s: %v", method, err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+func domainStatus(err *domainerr.Error) *status.Status {
+	code, ok := kindCodes[err.Kind]
+	if !ok {
+		code = codes.Unknown
+	}
+	st := status.New(code, err.Message)
+
+	var details []protoadapt.MessageV1
+	if err.Resource != nil {
+		details = append(details, &errdetails.ResourceInfo{
+			ResourceType: err.Resource.Type,
+			ResourceName: err.Resource.Name,
+			Description:  err.Message,
+		})
+	}
+	if len(err.Violations) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, violation := range err.Violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       violation.Field,
+				Description: violation.Description,
+			})
+		}
+		details = append(details, badRequest)
+	}
+	if err.Info != nil {
+		details = append(details, &errdetails.ErrorInfo{
+			Reason:   err.Info.Reason,
+			Domain:   errorInfoDomain,
+			Metadata: err.Info.Metadata,
+		})
+	}
+	if len(details) == 0 {
+		return st
+	}
+
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		return st
+	}
+	return withDetails
+}
@@ -0,0 +1,118 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+)
+
+func TestErrorUnary(t *testing.T) {
+	unary, _ := NewErrorInterceptors()
+	info := &grpc.UnaryServerInfo{FullMethod: "/ItemService/GetItem"}
+
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{"not found", fmt.Errorf("failed to get item: %w", domainerr.ResourceNotFound("item", "42")), codes.NotFound, "item 42 not found"},
+		{"already exists", domainerr.ResourceExists("user", "a@example.com"), codes.AlreadyExists, "user a@example.com already exists"},
+		{"invalid argument", domainerr.Invalid("page_size", "too large"), codes.InvalidArgument, "page_size: too large"},
+		{"failed precondition", domainerr.New(domainerr.FailedPrecondition, "order is paid"), codes.FailedPrecondition, "order is paid"},
+		{"conflict", domainerr.New(domainerr.Conflict, "modified concurrently"), codes.Aborted, "modified concurrently"},
+		{"permission denied", domainerr.New(domainerr.PermissionDenied, "wrong token"), codes.PermissionDenied, "wrong token"},
+		{"unknown kind", domainerr.New(domainerr.Kind("odd"), "odd"), codes.Unknown, "odd"},
+		{"status passes through", status.Error(codes.Unauthenticated, "invalid refresh token"), codes.Unauthenticated, "invalid refresh token"},
+		{"canceled", fmt.Errorf("query: %w", context.Canceled), codes.Canceled, "query: context canceled"},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), codes.DeadlineExceeded, "query: context deadline exceeded"},
+		{"internal", errors.New("dial tcp 10.0.0.5:5432: connection refused"), codes.Internal, "internal error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := unary(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+				return nil, tt.err
+			})
+
+			st, ok := status.FromError(err)
+			if !ok {
+				t.Fatalf("error %v is not a status", err)
+			}
+			if st.Code() != tt.wantCode || st.Message() != tt.wantMessage {
+				t.Errorf("status = %v %q, want %v %q", st.Code(), st.Message(), tt.wantCode, tt.wantMessage)
+			}
+		})
+	}
+
+	t.Run("success", func(t *testing.T) {
+		resp, err := unary(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+			return "ok", nil
+		})
+		if resp != "ok" || err != nil {
+			t.Errorf("unary() = %v, %v, want ok", resp, err)
+		}
+	})
+}
+
+func TestDomainStatusDetails(t *testing.T) {
+	st := domainStatus(domainerr.Invalid("user.email", "must be an email address").WithResource("user", "user-1"))
+
+	var resource *errdetails.ResourceInfo
+	var badRequest *errdetails.BadRequest
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ResourceInfo:
+			resource = d
+		case *errdetails.BadRequest:
+			badRequest = d
+		}
+	}
+
+	if resource == nil || resource.GetResourceType() != "user" || resource.GetResourceName() != "user-1" {
+		t.Errorf("ResourceInfo = %v", resource)
+	}
+	if badRequest == nil || len(badRequest.GetFieldViolations()) != 1 {
+		t.Fatalf("BadRequest = %v", badRequest)
+	}
+	violation := badRequest.GetFieldViolations()[0]
+	if violation.GetField() != "user.email" || violation.GetDescription() != "must be an email address" {
+		t.Errorf("field violation = %v", violation)
+	}
+
+	if plain := domainStatus(domainerr.New(domainerr.FailedPrecondition, "no details")); len(plain.Details()) != 0 {
+		t.Errorf("details = %v, want none", plain.Details())
+	}
+}
+
+func TestDomainStatusVersionMismatch(t *testing.T) {
+	st := domainStatus(domainerr.VersionMismatch("item", "item-1", 4))
+
+	if st.Code() != codes.Aborted {
+		t.Errorf("code = %v, want Aborted", st.Code())
+	}
+	if st.Message() != "item item-1 has been modified, current version is 4" {
+		t.Errorf("message = %q", st.Message())
+	}
+
+	var info *errdetails.ErrorInfo
+	for _, detail := range st.Details() {
+		if d, ok := detail.(*errdetails.ErrorInfo); ok {
+			info = d
+		}
+	}
+	if info == nil {
+		t.Fatalf("details = %v, want an ErrorInfo", st.Details())
+	}
+	if info.GetReason() != "VERSION_MISMATCH" || info.GetDomain() != errorInfoDomain || info.GetMetadata()["current_version"] != "4" {
+		t.Errorf("ErrorInfo = %v", info)
+	}
+}
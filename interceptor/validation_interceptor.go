@@ -0,0 +1,49 @@
+package interceptor
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/daffaromero/gorpc-template/helper/validator"
+)
+
+// NewValidationInterceptors returns unary and stream interceptors that check
+// every request against the rules declared in api.proto. Invalid requests
+// fail with an InvalidArgument domain error, so they should run after the
+// error interceptor. They should also run after the auth interceptors, so that
+// callers who may not use a method learn nothing about its request schema.
+func NewValidationInterceptors() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	return validateUnary, validateStream
+}
+
+func validateUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if msg, ok := req.(proto.Message); ok {
+		if err := validator.Validate(msg); err != nil {
+			return nil, err
+		}
+	}
+
+	return handler(ctx, req)
+}
+
+func validateStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &validatingStream{ServerStream: ss})
+}
+
+// validatingStream validates each message received from the client.
+type validatingStream struct {
+	grpc.ServerStream
+}
+
+func (s *validatingStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	if msg, ok := m.(proto.Message); ok {
+		return validator.Validate(msg)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+)
+
+func TestValidationUnary(t *testing.T) {
+	unary, _ := NewValidationInterceptors()
+	info := &grpc.UnaryServerInfo{FullMethod: "/ItemService/GetItem"}
+
+	called := false
+	handler := func(ctx context.Context, req any) (any, error) {
+		called = true
+		return nil, nil
+	}
+
+	if _, err := unary(context.Background(), &api.GetItemRequest{Id: "not-a-uuid"}, info, handler); !errors.Is(err, domainerr.InvalidArgument) {
+		t.Errorf("unary() = %v, want InvalidArgument", err)
+	}
+	if called {
+		t.Error("handler called with an invalid request")
+	}
+
+	if _, err := unary(context.Background(), &api.GetItemRequest{Id: "0191c5f2-7c1e-7d3a-9f00-000000000001"}, info, handler); err != nil {
+		t.Errorf("unary() = %v, want nil", err)
+	}
+	if !called {
+		t.Error("handler not called with a valid request")
+	}
+}
+
+// recvStream receives msg into every RecvMsg call.
+type recvStream struct {
+	grpc.ServerStream
+	msg *api.GetItemRequest
+}
+
+func (s *recvStream) RecvMsg(m any) error {
+	m.(*api.GetItemRequest).Id = s.msg.Id
+	return nil
+}
+
+func TestValidationStream(t *testing.T) {
+	_, stream := NewValidationInterceptors()
+	info := &grpc.StreamServerInfo{FullMethod: "/Test/Stream"}
+
+	tests := []struct {
+		name    string
+		id      string
+		wantErr bool
+	}{
+		{"valid message", "0191c5f2-7c1e-7d3a-9f00-000000000001", false},
+		{"invalid message", "not-a-uuid", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := &recvStream{msg: &api.GetItemRequest{Id: tt.id}}
+
+			err := stream(nil, ss, info, func(srv any, ss grpc.ServerStream) error {
+				return ss.RecvMsg(&api.GetItemRequest{})
+			})
+
+			if got := errors.Is(err, domainerr.InvalidArgument); got != tt.wantErr {
+				t.Errorf("RecvMsg() = %v, want InvalidArgument %v", err, tt.wantErr)
+			}
+		})
+	}
+}
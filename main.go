@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"google.golang.org/grpc"
+
+	"github.com/daffaromero/gorpc-template/config"
+	"github.com/daffaromero/gorpc-template/events"
+	logs "github.com/daffaromero/gorpc-template/helper/logger"
+	"github.com/daffaromero/gorpc-template/helper/password"
+	"github.com/daffaromero/gorpc-template/helper/token"
+	"github.com/daffaromero/gorpc-template/interceptor"
+	"github.com/daffaromero/gorpc-template/migrations"
+	"github.com/daffaromero/gorpc-template/outbox"
+	"github.com/daffaromero/gorpc-template/payment"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/purge"
+	"github.com/daffaromero/gorpc-template/repository"
+	"github.com/daffaromero/gorpc-template/repository/query"
+	"github.com/daffaromero/gorpc-template/service"
+	"github.com/daffaromero/gorpc-template/webhook"
+)
+
+func main() {
+	logger := logs.New("main")
+
+	serverConfig := config.LoadServerConfig()
+	flag.StringVar(&serverConfig.GRPCAddress, "addr", serverConfig.GRPCAddress, "gRPC listen address")
+	migrateOnStart := flag.Bool("migrate", false, "apply pending migrations before serving")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	dbConfig, err := config.LoadDBConfig()
+	if err != nil {
+		logger.Fatal("Failed to load database configuration: %v", err)
+	}
+
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrateCommand(dbConfig, flag.Args()[1:]); err != nil {
+			logger.Fatal("Migration failed: %v", err)
+		}
+		return
+	}
+
+	if *migrateOnStart {
+		if err := migrateUp(dbConfig); err != nil {
+			logger.Fatal("Failed to apply migrations: %v", err)
+		}
+	}
+
+	db, err := config.NewPostgresDatabase(dbConfig)
+	if err != nil {
+		logger.Fatal("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	passwordConfig, err := config.LoadPasswordConfig()
+	if err != nil {
+		logger.Fatal("Failed to load password configuration: %v", err)
+	}
+
+	hasher, err := password.NewBcryptHasher(passwordConfig.BcryptCost)
+	if err != nil {
+		logger.Fatal("Failed to create password hasher: %v", err)
+	}
+
+	authConfig, err := config.LoadAuthConfig()
+	if err != nil {
+		logger.Fatal("Failed to load auth configuration: %v", err)
+	}
+
+	tokenManager, err := token.NewManager(token.Config{
+		Issuer:        authConfig.Issuer,
+		AccessSecret:  []byte(authConfig.AccessSecret),
+		RefreshSecret: []byte(authConfig.RefreshSecret),
+		AccessTTL:     authConfig.AccessTTL,
+		RefreshTTL:    authConfig.RefreshTTL,
+	})
+	if err != nil {
+		logger.Fatal("Failed to create token manager: %v", err)
+	}
+
+	outboxConfig, err := config.LoadOutboxConfig()
+	if err != nil {
+		logger.Fatal("Failed to load outbox configuration: %v", err)
+	}
+
+	eventsConfig, err := config.LoadEventsConfig()
+	if err != nil {
+		logger.Fatal("Failed to load events configuration: %v", err)
+	}
+
+	bus, err := newEventBus(ctx, eventsConfig)
+	if err != nil {
+		logger.Fatal("Failed to create event bus: %v", err)
+	}
+	defer bus.Close()
+
+	paymentConfig, err := config.LoadPaymentConfig()
+	if err != nil {
+		logger.Fatal("Failed to load payment configuration: %v", err)
+	}
+
+	paymentProvider := payment.NewFakeProvider(paymentConfig.FakeBaseURL, paymentConfig.FakeSecret)
+
+	webhookConfig, err := config.LoadWebhookConfig()
+	if err != nil {
+		logger.Fatal("Failed to load webhook configuration: %v", err)
+	}
+
+	purgeConfig, err := config.LoadPurgeConfig()
+	if err != nil {
+		logger.Fatal("Failed to load purge configuration: %v", err)
+	}
+
+	inventoryConfig, err := config.LoadInventoryConfig()
+	if err != nil {
+		logger.Fatal("Failed to load inventory configuration: %v", err)
+	}
+
+	var replica *repository.Replica
+	if dbConfig.ReplicaHost != "" {
+		replicaDB, err := config.NewReplicaDatabase(dbConfig)
+		if err != nil {
+			logger.Fatal("Failed to configure replica: %v", err)
+		}
+		defer replicaDB.Close()
+
+		replica = repository.NewReplica(replicaDB, dbConfig.ReplicaMaxLag, dbConfig.ReplicaCheckInterval)
+		go replica.Run(ctx)
+	}
+
+	store := repository.NewStore(db, replica, *dbConfig)
+	outboxQuery := query.NewOutboxQuery(db)
+	refreshTokenQuery := query.NewRefreshTokenQuery(db)
+
+	itemQuery := query.NewItemQuery(db)
+	userQuery := query.NewUserQuery(db)
+	orderQuery := query.NewOrderQuery(db)
+	inventoryQuery := query.NewInventoryQuery(db)
+
+	itemRepository := repository.NewItemRepository(store, itemQuery, inventoryQuery, outboxQuery, inventoryConfig.LowStockThreshold)
+	userRepository := repository.NewUserRepository(store, userQuery, refreshTokenQuery, outboxQuery, hasher)
+	orderRepository := repository.NewOrderRepository(store, orderQuery, inventoryQuery, outboxQuery, query.NewPaymentWebhookQuery(db), inventoryConfig.LowStockThreshold)
+	sellerRepository := repository.NewSellerRepository(store, query.NewSellerQuery(db), itemQuery, outboxQuery)
+	refreshTokenRepository := repository.NewRefreshTokenRepository(store, refreshTokenQuery)
+
+	authUnary, authStream := interceptor.NewAuthInterceptors(tokenManager, service.NewAccessPolicy(itemRepository, orderRepository, sellerRepository))
+
+	errorUnary, errorStream := interceptor.NewErrorInterceptors()
+	validationUnary, validationStream := interceptor.NewValidationInterceptors()
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(errorUnary, authUnary, validationUnary),
+		grpc.ChainStreamInterceptor(errorStream, authStream, validationStream),
+	)
+	api.RegisterAuthServiceServer(server, service.NewAuthService(userRepository, refreshTokenRepository, tokenManager))
+	api.RegisterItemServiceServer(server, service.NewItemService(itemRepository))
+	api.RegisterUserServiceServer(server, service.NewUserService(userRepository))
+	api.RegisterOrderServiceServer(server, service.NewOrderService(orderRepository, paymentProvider))
+	api.RegisterSellerServiceServer(server, service.NewSellerService(sellerRepository))
+
+	listener, err := net.Listen("tcp", serverConfig.GRPCAddress)
+	if err != nil {
+		logger.Fatal("Failed to listen on %s: %v", serverConfig.GRPCAddress, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/webhooks/payments", webhook.NewPaymentHandler(orderRepository, webhookConfig.PaymentSecret))
+	webhookServer := &http.Server{Addr: webhookConfig.Address, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/debug/vars", expvar.Handler())
+	adminServer := &http.Server{Addr: serverConfig.AdminAddress, Handler: adminMux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		logger.Info("Webhook server listening on %s", webhookConfig.Address)
+		if err := webhookServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal("Webhook server stopped: %v", err)
+		}
+	}()
+
+	go func() {
+		logger.Info("Admin server listening on %s", serverConfig.AdminAddress)
+		if err := adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal("Admin server stopped: %v", err)
+		}
+	}()
+
+	relay := outbox.NewRelay(store, outboxQuery, bus, outboxConfig.PollInterval, outboxConfig.BatchSize)
+	go relay.Run(ctx)
+
+	purger := purge.NewPurger(store, userQuery, orderQuery, purgeConfig.Retention, purgeConfig.Interval)
+	go purger.Run(ctx)
+
+	go func() {
+		<-ctx.Done()
+		logger.Info("Shutting down gRPC, webhook and admin servers")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := webhookServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Failed to shut down webhook server: %v", err)
+		}
+		if err := adminServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Failed to shut down admin server: %v", err)
+		}
+
+		server.GracefulStop()
+	}()
+
+	logger.Info("gRPC server listening on %s", serverConfig.GRPCAddress)
+	if err := server.Serve(listener); err != nil {
+		logger.Fatal("gRPC server stopped: %v", err)
+	}
+}
+
+func migrateUp(dbConfig *config.DBConfig) error {
+	migrator, err := migrations.NewMigrator(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	return migrator.Up(0)
+}
+
+func newEventBus(ctx context.Context, eventsConfig *config.EventsConfig) (events.Bus, error) {
+	if eventsConfig.Bus != config.EventBusNATS {
+		return events.NewMemoryBus(), nil
+	}
+
+	nc, err := nats.Connect(eventsConfig.NATSURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	bus, err := events.NewJetStreamBus(ctx, nc, eventsConfig.NATSStream)
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+
+	return bus, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/daffaromero/gorpc-template/config"
+	"github.com/daffaromero/gorpc-template/migrations"
+)
+
+const migrateUsage = "usage: migrate up [N] | down [N] | to VERSION | status | force VERSION"
+
+// runMigrateCommand runs the migrate subcommand with the arguments that
+// follow "migrate" on the command line.
+func runMigrateCommand(dbConfig *config.DBConfig, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errors.New(migrateUsage)
+	}
+
+	command, arg := args[0], ""
+	if len(args) == 2 {
+		arg = args[1]
+	}
+
+	migrator, err := migrations.NewMigrator(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	switch command {
+	case "up":
+		var n int
+		if n, err = optionalCount(arg, 0); err == nil {
+			err = migrator.Up(n)
+		}
+	case "down":
+		var n int
+		if n, err = optionalCount(arg, 1); err == nil {
+			err = migrator.Down(n)
+		}
+	case "to":
+		version, parseErr := strconv.ParseUint(arg, 10, 64)
+		if parseErr != nil {
+			return fmt.Errorf("invalid version %q: %s", arg, migrateUsage)
+		}
+		err = migrator.To(uint(version))
+	case "force":
+		version, parseErr := strconv.Atoi(arg)
+		if parseErr != nil || version < -1 {
+			return fmt.Errorf("invalid version %q: %s", arg, migrateUsage)
+		}
+		err = migrator.Force(version)
+	case "status":
+		if arg != "" {
+			return errors.New(migrateUsage)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+	if err != nil {
+		return err
+	}
+
+	return printMigrationStatus(migrator)
+}
+
+func printMigrationStatus(migrator *migrations.Migrator) error {
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	dirty := ""
+	if status.Dirty {
+		dirty = " (dirty, fix the schema and run force)"
+	}
+	fmt.Printf("version: %d%s\n", status.Version, dirty)
+	fmt.Printf("pending: %d %v\n", len(status.Pending), status.Pending)
+
+	return nil
+}
+
+func optionalCount(arg string, fallback int) (int, error) {
+	if arg == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(arg)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid count %q: %s", arg, migrateUsage)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/daffaromero/gorpc-template/config"
+)
+
+func TestRunMigrateCommandUsage(t *testing.T) {
+	// Argument counts are checked before connecting to the database.
+	for _, args := range [][]string{nil, {"up", "1", "2"}} {
+		if err := runMigrateCommand(&config.DBConfig{}, args); err == nil || err.Error() != migrateUsage {
+			t.Errorf("runMigrateCommand(%q) = %v, want the usage", args, err)
+		}
+	}
+}
+
+func TestOptionalCount(t *testing.T) {
+	tests := []struct {
+		arg     string
+		want    int
+		wantErr bool
+	}{
+		{"", 7, false},
+		{"3", 3, false},
+		{"0", 0, true},
+		{"-1", 0, true},
+		{"all", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := optionalCount(tt.arg, 7)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("optionalCount(%q, 7) = %d, %v, want %d, error %v", tt.arg, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+CREATE INDEX idx_items_name_id ON items (name, id);
+
+CREATE INDEX idx_orders_created_at_id ON orders (created_at DESC, id DESC);
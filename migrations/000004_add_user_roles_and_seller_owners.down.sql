@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_sellers_owner_id;
+
+ALTER TABLE sellers DROP COLUMN IF EXISTS owner_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS role;
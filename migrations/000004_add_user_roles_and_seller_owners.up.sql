@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN role VARCHAR(50) NOT NULL DEFAULT 'user';
+
+ALTER TABLE sellers ADD COLUMN owner_id UUID REFERENCES users(id);
+
+CREATE INDEX idx_sellers_owner_id ON sellers (owner_id);
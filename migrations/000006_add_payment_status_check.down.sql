@@ -0,0 +1,3 @@
+ALTER TABLE orders DROP CONSTRAINT orders_payment_status_check;
+
+ALTER TABLE orders ALTER COLUMN payment_status DROP DEFAULT;
@@ -0,0 +1,4 @@
+ALTER TABLE orders ALTER COLUMN payment_status SET DEFAULT 'pending';
+
+ALTER TABLE orders ADD CONSTRAINT orders_payment_status_check
+    CHECK (payment_status IN ('pending', 'paid', 'failed', 'expired', 'refunded'));
@@ -0,0 +1,6 @@
+CREATE TABLE payment_webhook_events (
+    event_id VARCHAR(255) PRIMARY KEY,
+    order_id UUID NOT NULL,
+    payment_status VARCHAR(50) NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
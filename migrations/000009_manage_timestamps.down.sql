@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS sellers_set_updated_at ON sellers;
+DROP TRIGGER IF EXISTS items_set_updated_at ON items;
+DROP TRIGGER IF EXISTS orders_set_updated_at ON orders;
+DROP TRIGGER IF EXISTS users_set_updated_at ON users;
+DROP FUNCTION IF EXISTS set_updated_at();
+
+ALTER TABLE sellers DROP COLUMN created_at, DROP COLUMN updated_at;
+ALTER TABLE items DROP COLUMN created_at, DROP COLUMN updated_at;
+
+ALTER TABLE orders ALTER COLUMN updated_at DROP DEFAULT;
+ALTER TABLE orders ALTER COLUMN created_at DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN updated_at DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN created_at DROP DEFAULT;
@@ -0,0 +1,28 @@
+ALTER TABLE users ALTER COLUMN created_at SET DEFAULT NOW();
+ALTER TABLE users ALTER COLUMN updated_at SET DEFAULT NOW();
+ALTER TABLE orders ALTER COLUMN created_at SET DEFAULT NOW();
+ALTER TABLE orders ALTER COLUMN updated_at SET DEFAULT NOW();
+
+ALTER TABLE items
+    ADD COLUMN created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+ALTER TABLE sellers
+    ADD COLUMN created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+
+-- updated_at is maintained here rather than by every UPDATE statement.
+CREATE FUNCTION set_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_set_updated_at BEFORE UPDATE ON users
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER orders_set_updated_at BEFORE UPDATE ON orders
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER items_set_updated_at BEFORE UPDATE ON items
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER sellers_set_updated_at BEFORE UPDATE ON sellers
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_items_seller_id_name_id;
+
+ALTER TABLE items DROP COLUMN seller_id;
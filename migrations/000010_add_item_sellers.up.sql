@@ -0,0 +1,5 @@
+-- Sellers with items cannot be deleted; their items must be removed or
+-- reassigned first.
+ALTER TABLE items ADD COLUMN seller_id UUID REFERENCES sellers(id) ON DELETE RESTRICT;
+
+CREATE INDEX idx_items_seller_id_name_id ON items (seller_id, name, id);
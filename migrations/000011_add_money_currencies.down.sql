@@ -0,0 +1,6 @@
+ALTER TABLE orders DROP COLUMN currency;
+ALTER TABLE orders ALTER COLUMN total_price TYPE INTEGER;
+
+ALTER TABLE items DROP CONSTRAINT items_price_check;
+ALTER TABLE items DROP COLUMN currency;
+ALTER TABLE items ALTER COLUMN price TYPE INTEGER;
@@ -0,0 +1,10 @@
+ALTER TABLE items ALTER COLUMN price TYPE BIGINT;
+ALTER TABLE items ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE items ALTER COLUMN currency DROP DEFAULT;
+ALTER TABLE items ADD CONSTRAINT items_price_check CHECK (price >= 0);
+
+-- The currency of an order is only known once its line items are written,
+-- so it is set together with total_price in the same transaction.
+ALTER TABLE orders ALTER COLUMN total_price TYPE BIGINT;
+ALTER TABLE orders ADD COLUMN currency CHAR(3);
+UPDATE orders SET currency = 'USD';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS stock_reservations;
+
+ALTER TABLE items DROP COLUMN stock;
@@ -0,0 +1,15 @@
+ALTER TABLE items ADD COLUMN stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0);
+
+-- items.stock is the quantity still available. Reserved stock has already
+-- been taken out of it and is returned when a reservation is released.
+CREATE TABLE stock_reservations (
+    order_id UUID NOT NULL REFERENCES orders(id),
+    item_id UUID NOT NULL REFERENCES items(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('reserved', 'committed', 'released')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (order_id, item_id)
+);
+
+CREATE INDEX idx_stock_reservations_item_id ON stock_reservations (item_id);
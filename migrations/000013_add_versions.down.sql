@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS sellers_increment_version ON sellers;
+DROP TRIGGER IF EXISTS items_increment_version ON items;
+DROP TRIGGER IF EXISTS users_increment_version ON users;
+DROP FUNCTION IF EXISTS increment_version();
+
+ALTER TABLE sellers DROP COLUMN version;
+ALTER TABLE items DROP COLUMN version;
+ALTER TABLE orders DROP COLUMN version;
+ALTER TABLE users DROP COLUMN version;
@@ -0,0 +1,30 @@
+ALTER TABLE users ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE orders ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE items ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE sellers ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+-- version only changes with the columns clients edit through the Update RPCs,
+-- so stock, payment and soft-delete changes do not fail concurrent updates.
+CREATE FUNCTION increment_version() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_increment_version BEFORE UPDATE ON users
+    FOR EACH ROW
+    WHEN ((OLD.name, OLD.email, OLD.password) IS DISTINCT FROM (NEW.name, NEW.email, NEW.password))
+    EXECUTE FUNCTION increment_version();
+CREATE TRIGGER items_increment_version BEFORE UPDATE ON items
+    FOR EACH ROW
+    WHEN ((OLD.name, OLD.description, OLD.seller_id, OLD.price, OLD.currency)
+        IS DISTINCT FROM (NEW.name, NEW.description, NEW.seller_id, NEW.price, NEW.currency))
+    EXECUTE FUNCTION increment_version();
+CREATE TRIGGER sellers_increment_version BEFORE UPDATE ON sellers
+    FOR EACH ROW
+    WHEN (OLD.name IS DISTINCT FROM NEW.name)
+    EXECUTE FUNCTION increment_version();
+
+-- The line items of an order live in order_items, so UpdateOrder increments
+-- orders.version itself instead of relying on a trigger.
@@ -0,0 +1,145 @@
+// Package migrations embeds the SQL migrations in this directory and applies
+// them with golang-migrate.
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+
+	"github.com/daffaromero/gorpc-template/config"
+	logs "github.com/daffaromero/gorpc-template/helper/logger"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// lockTimeout bounds how long a migration waits for the advisory lock held
+// by another instance.
+const lockTimeout = 5 * time.Minute
+
+// Status describes the schema version of a database.
+type Status struct {
+	// Version is the last applied migration, 0 if none was applied.
+	Version uint
+	// Dirty is set when a migration failed halfway and the schema needs
+	// fixing by hand before Force.
+	Dirty   bool
+	Pending []uint
+}
+
+// Migrator applies the embedded migrations. Every operation holds a
+// Postgres advisory lock, so instances starting together apply each
+// migration once.
+type Migrator struct {
+	m      *migrate.Migrate
+	source source.Driver
+}
+
+func NewMigrator(dbConfig *config.DBConfig) (*Migrator, error) {
+	sourceDriver, err := iofs.New(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+
+	dsn := url.URL{
+		Scheme: "pgx5",
+		User:   url.UserPassword(dbConfig.Username, dbConfig.Password),
+		Host:   dbConfig.Host + ":" + dbConfig.Port,
+		Path:   "/" + dbConfig.DBName,
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", sourceDriver, dsn.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	m.LockTimeout = lockTimeout
+	m.Log = &logAdapter{logger: logs.New("migrations")}
+
+	return &Migrator{m: m, source: sourceDriver}, nil
+}
+
+// Up applies all pending migrations, or at most n of them when n > 0.
+func (m *Migrator) Up(n int) error {
+	if n > 0 {
+		return ignoreNoChange(m.m.Steps(n))
+	}
+	return ignoreNoChange(m.m.Up())
+}
+
+// Down rolls back the last n migrations.
+func (m *Migrator) Down(n int) error {
+	if n <= 0 {
+		return fmt.Errorf("number of migrations to roll back must be positive, got %d", n)
+	}
+	return ignoreNoChange(m.m.Steps(-n))
+}
+
+// To migrates up or down to version.
+func (m *Migrator) To(version uint) error {
+	return ignoreNoChange(m.m.Migrate(version))
+}
+
+// Force sets the schema version without running any migration and clears
+// the dirty flag. A version of -1 marks the database as unmigrated.
+func (m *Migrator) Force(version int) error {
+	return m.m.Force(version)
+}
+
+func (m *Migrator) Status() (*Status, error) {
+	var status Status
+
+	version, dirty, err := m.m.Version()
+	switch {
+	case errors.Is(err, migrate.ErrNilVersion):
+	case err != nil:
+		return nil, err
+	default:
+		status.Version, status.Dirty = version, dirty
+	}
+
+	next, err := m.source.First()
+	for err == nil {
+		if next > status.Version {
+			status.Pending = append(status.Pending, next)
+		}
+		next, err = m.source.Next(next)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.m.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+type logAdapter struct {
+	logger *logs.Log
+}
+
+func (a *logAdapter) Printf(format string, v ...any) {
+	a.logger.Info(format, v...)
+}
+
+func (a *logAdapter) Verbose() bool {
+	return false
+}
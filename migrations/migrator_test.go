@@ -0,0 +1,71 @@
+package migrations
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// TestEmbeddedMigrations checks that versions run from 1 without gaps and
+// that every migration can be rolled back.
+func TestEmbeddedMigrations(t *testing.T) {
+	sourceDriver, err := iofs.New(files, ".")
+	if err != nil {
+		t.Fatalf("iofs.New() = %v", err)
+	}
+	defer sourceDriver.Close()
+
+	version, err := sourceDriver.First()
+	if err != nil {
+		t.Fatalf("First() = %v", err)
+	}
+	if version != 1 {
+		t.Errorf("first version = %d, want 1", version)
+	}
+
+	for {
+		for direction, read := range map[string]func(uint) (io.ReadCloser, string, error){
+			"up":   sourceDriver.ReadUp,
+			"down": sourceDriver.ReadDown,
+		} {
+			r, identifier, err := read(version)
+			if err != nil {
+				t.Errorf("version %d has no %s migration: %v", version, direction, err)
+				continue
+			}
+			body, err := io.ReadAll(r)
+			r.Close()
+			if err != nil || strings.TrimSpace(string(body)) == "" {
+				t.Errorf("%s migration %d %s is empty", direction, version, identifier)
+			}
+		}
+
+		next, err := sourceDriver.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next(%d) = %v", version, err)
+		}
+		if next != version+1 {
+			t.Errorf("version %d follows %d", next, version)
+		}
+		version = next
+	}
+}
+
+func TestIgnoreNoChange(t *testing.T) {
+	if err := ignoreNoChange(migrate.ErrNoChange); err != nil {
+		t.Errorf("ignoreNoChange(ErrNoChange) = %v", err)
+	}
+
+	err := errors.New("dirty database")
+	if got := ignoreNoChange(err); got != err {
+		t.Errorf("ignoreNoChange(%v) = %v", err, got)
+	}
+}
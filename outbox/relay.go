@@ -0,0 +1,119 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/daffaromero/gorpc-template/events"
+	logs "github.com/daffaromero/gorpc-template/helper/logger"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository"
+	"github.com/daffaromero/gorpc-template/repository/query"
+
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Relay periodically moves pending outbox events to the event bus.
+//
+// Events are published while their rows are locked and marked dispatched in
+// the same transaction. A crash between publishing and committing therefore
+// publishes the event again, which gives at-least-once delivery; consumers
+// must de-duplicate by event ID. For the same reason the transaction is never
+// retried by Store: a failed batch is picked up again on the next tick.
+type Relay struct {
+	db          repository.Store
+	outboxQuery query.OutboxQuery
+	publisher   events.Publisher
+	interval    time.Duration
+	batchSize   int32
+	logger      *logs.Log
+}
+
+func NewRelay(db repository.Store, outboxQuery query.OutboxQuery, publisher events.Publisher, interval time.Duration, batchSize int32) *Relay {
+	return &Relay{
+		db:          db,
+		outboxQuery: outboxQuery,
+		publisher:   publisher,
+		interval:    interval,
+		batchSize:   batchSize,
+		logger:      logs.New("outbox_relay"),
+	}
+}
+
+// Run relays events until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		// Keep draining while full batches come back, then wait for the next tick.
+		for {
+			dispatched, err := r.relayBatch(ctx)
+			if err != nil {
+				r.logger.Error("Failed to relay outbox events: %v", err)
+				break
+			}
+			if dispatched < int(r.batchSize) {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Relay) relayBatch(ctx context.Context) (int, error) {
+	var dispatched []string
+
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		pending, err := r.outboxQuery.ListPendingEvents(ctx, tx, r.batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list pending events: %w", err)
+		}
+
+		var publishErr error
+		for _, event := range pending {
+			topic := events.Topic(event.AggregateType, event.EventType)
+			if publishErr = r.publisher.Publish(ctx, topic, envelope(event)); publishErr != nil {
+				// Stop here so events of one aggregate keep their order.
+				publishErr = fmt.Errorf("failed to publish event %s: %w", event.ID, publishErr)
+				break
+			}
+			dispatched = append(dispatched, event.ID)
+		}
+
+		if len(dispatched) > 0 {
+			if err := r.outboxQuery.MarkEventsDispatched(ctx, tx, dispatched); err != nil {
+				return fmt.Errorf("failed to mark events dispatched: %w", err)
+			}
+		}
+
+		if publishErr != nil {
+			r.logger.Warn("%v", publishErr)
+		}
+		return nil
+	}, repository.NoRetry())
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(dispatched), nil
+}
+
+func envelope(event *query.OutboxEvent) *api.EventEnvelope {
+	return &api.EventEnvelope{
+		Id:            event.ID,
+		Type:          event.EventType,
+		OccurredAt:    timestamppb.New(event.CreatedAt),
+		AggregateType: event.AggregateType,
+		AggregateId:   event.AggregateID,
+		Payload:       event.Payload,
+	}
+}
@@ -0,0 +1,150 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/daffaromero/gorpc-template/events"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository"
+	"github.com/daffaromero/gorpc-template/repository/query"
+)
+
+// fakeStore runs fn once with a nil transaction and counts the calls.
+type fakeStore struct {
+	repository.Store
+	calls int
+}
+
+func (s *fakeStore) WithTx(ctx context.Context, fn func(ctx context.Context, tx pgx.Tx) error, opts ...repository.TxOption) error {
+	s.calls++
+	return fn(ctx, nil)
+}
+
+// fakeOutboxQuery serves pending events in order and drops those marked
+// dispatched.
+type fakeOutboxQuery struct {
+	query.OutboxQuery
+	pending    []*query.OutboxEvent
+	dispatched []string
+}
+
+func (q *fakeOutboxQuery) ListPendingEvents(ctx context.Context, tx pgx.Tx, limit int32) ([]*query.OutboxEvent, error) {
+	return q.pending[:min(int(limit), len(q.pending))], nil
+}
+
+func (q *fakeOutboxQuery) MarkEventsDispatched(ctx context.Context, tx pgx.Tx, ids []string) error {
+	q.dispatched = append(q.dispatched, ids...)
+	q.pending = slices.DeleteFunc(q.pending, func(event *query.OutboxEvent) bool {
+		return slices.Contains(ids, event.ID)
+	})
+	return nil
+}
+
+// fakePublisher records published envelopes and fails for event IDs in fail.
+type fakePublisher struct {
+	published []*api.EventEnvelope
+	topics    []string
+	fail      map[string]bool
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, topic string, envelope *api.EventEnvelope) error {
+	if p.fail[envelope.Id] {
+		return errors.New("bus unavailable")
+	}
+	p.published = append(p.published, envelope)
+	p.topics = append(p.topics, topic)
+	return nil
+}
+
+func pendingEvents(ids ...string) []*query.OutboxEvent {
+	pending := make([]*query.OutboxEvent, 0, len(ids))
+	for _, id := range ids {
+		pending = append(pending, &query.OutboxEvent{
+			ID:            id,
+			AggregateType: "order",
+			AggregateID:   "order-1",
+			EventType:     "OrderCreated",
+			Payload:       []byte(id),
+			CreatedAt:     time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		})
+	}
+	return pending
+}
+
+func TestRelayBatch(t *testing.T) {
+	outboxQuery := &fakeOutboxQuery{pending: pendingEvents("e1", "e2", "e3")}
+	publisher := &fakePublisher{}
+	relay := NewRelay(&fakeStore{}, outboxQuery, publisher, time.Second, 2)
+
+	dispatched, err := relay.relayBatch(context.Background())
+	if err != nil {
+		t.Fatalf("relayBatch() = %v", err)
+	}
+
+	if dispatched != 2 || !slices.Equal(outboxQuery.dispatched, []string{"e1", "e2"}) {
+		t.Errorf("dispatched %d events %v, want e1 and e2", dispatched, outboxQuery.dispatched)
+	}
+	if len(outboxQuery.pending) != 1 {
+		t.Errorf("%d events still pending, want 1", len(outboxQuery.pending))
+	}
+
+	envelope := publisher.published[0]
+	if envelope.Id != "e1" || envelope.Type != "OrderCreated" || envelope.AggregateType != "order" || envelope.AggregateId != "order-1" || string(envelope.Payload) != "e1" {
+		t.Errorf("envelope = %v", envelope)
+	}
+	if !envelope.OccurredAt.AsTime().Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("OccurredAt = %v", envelope.OccurredAt.AsTime())
+	}
+	if want := events.Topic("order", "OrderCreated"); publisher.topics[0] != want {
+		t.Errorf("topic = %q, want %q", publisher.topics[0], want)
+	}
+}
+
+func TestRelayBatchStopsAtFailedPublish(t *testing.T) {
+	outboxQuery := &fakeOutboxQuery{pending: pendingEvents("e1", "e2", "e3")}
+	publisher := &fakePublisher{fail: map[string]bool{"e2": true}}
+	relay := NewRelay(&fakeStore{}, outboxQuery, publisher, time.Second, 10)
+
+	dispatched, err := relay.relayBatch(context.Background())
+	if err != nil {
+		t.Fatalf("relayBatch() = %v", err)
+	}
+
+	// e3 must not overtake e2, so both stay pending for the next tick.
+	if dispatched != 1 || !slices.Equal(outboxQuery.dispatched, []string{"e1"}) {
+		t.Errorf("dispatched %d events %v, want only e1", dispatched, outboxQuery.dispatched)
+	}
+	if len(publisher.published) != 1 {
+		t.Errorf("published %d events, want 1", len(publisher.published))
+	}
+
+	publisher.fail = nil
+	if _, err := relay.relayBatch(context.Background()); err != nil {
+		t.Fatalf("relayBatch() = %v", err)
+	}
+	if !slices.Equal(outboxQuery.dispatched, []string{"e1", "e2", "e3"}) {
+		t.Errorf("dispatched %v after the bus recovered, want e1, e2, e3", outboxQuery.dispatched)
+	}
+}
+
+func TestRelayRunDrainsFullBatches(t *testing.T) {
+	store := &fakeStore{}
+	outboxQuery := &fakeOutboxQuery{pending: pendingEvents("e1", "e2", "e3", "e4", "e5")}
+	publisher := &fakePublisher{}
+	relay := NewRelay(store, outboxQuery, publisher, time.Hour, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	relay.Run(ctx)
+
+	// Two full batches and a short one, all before the first tick.
+	if store.calls != 3 || len(outboxQuery.pending) != 0 {
+		t.Errorf("ran %d batches leaving %d events, want 3 batches and none left", store.calls, len(outboxQuery.pending))
+	}
+}
@@ -0,0 +1,60 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+)
+
+// FakeProvider is a deterministic Provider for local runs and tests. The
+// token of an order is derived from its ID, and callbacks are signed with
+// HMAC-SHA256 over the order ID, token and status, so Sign can produce valid
+// callbacks without any gateway.
+type FakeProvider struct {
+	baseURL string
+	secret  []byte
+}
+
+func NewFakeProvider(baseURL, secret string) *FakeProvider {
+	return &FakeProvider{baseURL: strings.TrimRight(baseURL, "/"), secret: []byte(secret)}
+}
+
+func (p *FakeProvider) CreatePayment(ctx context.Context, orderID string, amount *api.Money) (*Payment, error) {
+	token := p.mac("token", orderID)[:32]
+
+	return &Payment{
+		URL: fmt.Sprintf("%s/%s?amount=%d&currency=%s&token=%s",
+			p.baseURL, url.PathEscape(orderID), amount.GetMinorUnits(), url.QueryEscape(amount.GetCurrencyCode()), token),
+		Token: token,
+	}, nil
+}
+
+func (p *FakeProvider) VerifyCallback(ctx context.Context, callback *Callback) error {
+	expected := p.signature(callback)
+	if !hmac.Equal([]byte(expected), []byte(callback.Signature)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Sign sets the signature the fake gateway would send with callback.
+func (p *FakeProvider) Sign(callback *Callback) {
+	callback.Signature = p.signature(callback)
+}
+
+func (p *FakeProvider) signature(callback *Callback) string {
+	return p.mac("callback", callback.OrderID, callback.Token, string(callback.Status))
+}
+
+func (p *FakeProvider) mac(parts ...string) string {
+	h := hmac.New(sha256.New, p.secret)
+	h.Write([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,87 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/daffaromero/gorpc-template/helper/money"
+)
+
+func TestFakeProviderCreatePayment(t *testing.T) {
+	ctx := context.Background()
+	provider := NewFakeProvider("https://pay.example.com/", "secret")
+
+	payment, err := provider.CreatePayment(ctx, "order-1", money.New("USD", 1250))
+	if err != nil {
+		t.Fatalf("CreatePayment() = %v", err)
+	}
+
+	if len(payment.Token) != 32 {
+		t.Errorf("token %q has length %d, want 32", payment.Token, len(payment.Token))
+	}
+	wantURL := "https://pay.example.com/order-1?amount=1250&currency=USD&token=" + payment.Token
+	if payment.URL != wantURL {
+		t.Errorf("URL = %q, want %q", payment.URL, wantURL)
+	}
+
+	again, _ := provider.CreatePayment(ctx, "order-1", money.New("USD", 1250))
+	if again.Token != payment.Token {
+		t.Error("tokens for the same order differ")
+	}
+
+	other, _ := provider.CreatePayment(ctx, "order-2", money.New("USD", 1250))
+	if other.Token == payment.Token {
+		t.Error("tokens for different orders are equal")
+	}
+
+	otherSecret, _ := NewFakeProvider("https://pay.example.com", "other").CreatePayment(ctx, "order-1", money.New("USD", 1250))
+	if otherSecret.Token == payment.Token || !strings.HasPrefix(otherSecret.URL, "https://pay.example.com/order-1?") {
+		t.Errorf("payment with another secret = %+v", otherSecret)
+	}
+}
+
+func TestFakeProviderVerifyCallback(t *testing.T) {
+	ctx := context.Background()
+	provider := NewFakeProvider("https://pay.example.com", "secret")
+
+	signed := func() *Callback {
+		callback := &Callback{OrderID: "order-1", Token: "token-1", Status: StatusPaid}
+		provider.Sign(callback)
+		return callback
+	}
+
+	if err := provider.VerifyCallback(ctx, signed()); err != nil {
+		t.Fatalf("VerifyCallback() of a signed callback = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(*Callback)
+	}{
+		{"unsigned", func(c *Callback) { c.Signature = "" }},
+		{"other order", func(c *Callback) { c.OrderID = "order-2" }},
+		{"other token", func(c *Callback) { c.Token = "token-2" }},
+		{"other status", func(c *Callback) { c.Status = StatusFailed }},
+		{"truncated signature", func(c *Callback) { c.Signature = c.Signature[:len(c.Signature)-2] }},
+		{"upper case signature", func(c *Callback) { c.Signature = strings.ToUpper(c.Signature) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			callback := signed()
+			tt.tamper(callback)
+			if err := provider.VerifyCallback(ctx, callback); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("VerifyCallback() = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+
+	t.Run("other secret", func(t *testing.T) {
+		other := NewFakeProvider("https://pay.example.com", "other")
+		if err := other.VerifyCallback(ctx, signed()); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("VerifyCallback() = %v, want ErrInvalidSignature", err)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package payment
+
+import (
+	"context"
+	"errors"
+
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid payment callback signature")
+	ErrTokenMismatch    = errors.New("payment token does not match the order")
+)
+
+// Payment is a payment started with a provider. URL is where the customer
+// pays and Token identifies the payment in provider callbacks.
+type Payment struct {
+	URL   string
+	Token string
+}
+
+// Callback is a payment status notification sent by a provider.
+type Callback struct {
+	OrderID   string
+	Token     string
+	Status    Status
+	Signature string
+}
+
+// Provider is a payment gateway.
+type Provider interface {
+	CreatePayment(ctx context.Context, orderID string, amount *api.Money) (*Payment, error)
+	// VerifyCallback returns ErrInvalidSignature unless the callback was
+	// signed by the provider.
+	VerifyCallback(ctx context.Context, callback *Callback) error
+}
@@ -0,0 +1,90 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+)
+
+// Status is the payment status of an order as stored in
+// orders.payment_status.
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusPaid     Status = "paid"
+	StatusFailed   Status = "failed"
+	StatusExpired  Status = "expired"
+	StatusRefunded Status = "refunded"
+)
+
+var (
+	ErrInvalidTransition = errors.New("invalid payment status transition")
+	ErrNotPending        = errors.New("payment is no longer pending")
+)
+
+// transitions lists the statuses each status may move to. Failed, expired
+// and refunded are final.
+var transitions = map[Status][]Status{
+	StatusPending: {StatusPaid, StatusFailed, StatusExpired},
+	StatusPaid:    {StatusRefunded},
+}
+
+// Transition returns an error wrapping ErrInvalidTransition unless an order
+// may move from one status to the other.
+func Transition(from, to Status) error {
+	for _, next := range transitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w from %s to %s", ErrInvalidTransition, from, to)
+}
+
+// ParseStatus returns false unless s is one of the statuses above.
+func ParseStatus(s string) (Status, bool) {
+	switch status := Status(s); status {
+	case StatusPending, StatusPaid, StatusFailed, StatusExpired, StatusRefunded:
+		return status, true
+	default:
+		return "", false
+	}
+}
+
+func (s Status) ToProto() api.PaymentStatus {
+	switch s {
+	case StatusPending:
+		return api.PaymentStatus_PAYMENT_STATUS_PENDING
+	case StatusPaid:
+		return api.PaymentStatus_PAYMENT_STATUS_PAID
+	case StatusFailed:
+		return api.PaymentStatus_PAYMENT_STATUS_FAILED
+	case StatusExpired:
+		return api.PaymentStatus_PAYMENT_STATUS_EXPIRED
+	case StatusRefunded:
+		return api.PaymentStatus_PAYMENT_STATUS_REFUNDED
+	default:
+		return api.PaymentStatus_PAYMENT_STATUS_UNSPECIFIED
+	}
+}
+
+// StatusFromProto returns false for PAYMENT_STATUS_UNSPECIFIED and unknown
+// values.
+func StatusFromProto(status api.PaymentStatus) (Status, bool) {
+	switch status {
+	case api.PaymentStatus_PAYMENT_STATUS_PENDING:
+		return StatusPending, true
+	case api.PaymentStatus_PAYMENT_STATUS_PAID:
+		return StatusPaid, true
+	case api.PaymentStatus_PAYMENT_STATUS_FAILED:
+		return StatusFailed, true
+	case api.PaymentStatus_PAYMENT_STATUS_EXPIRED:
+		return StatusExpired, true
+	case api.PaymentStatus_PAYMENT_STATUS_REFUNDED:
+		return StatusRefunded, true
+	default:
+		return "", false
+	}
+}
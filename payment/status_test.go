@@ -0,0 +1,70 @@
+package payment
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+)
+
+var allStatuses = []Status{StatusPending, StatusPaid, StatusFailed, StatusExpired, StatusRefunded}
+
+func TestTransition(t *testing.T) {
+	allowed := map[[2]Status]bool{
+		{StatusPending, StatusPaid}:    true,
+		{StatusPending, StatusFailed}:  true,
+		{StatusPending, StatusExpired}: true,
+		{StatusPaid, StatusRefunded}:   true,
+	}
+
+	for _, from := range allStatuses {
+		for _, to := range allStatuses {
+			err := Transition(from, to)
+			if allowed[[2]Status{from, to}] {
+				if err != nil {
+					t.Errorf("Transition(%s, %s) = %v, want nil", from, to, err)
+				}
+				continue
+			}
+			if !errors.Is(err, ErrInvalidTransition) {
+				t.Errorf("Transition(%s, %s) = %v, want ErrInvalidTransition", from, to, err)
+			}
+		}
+	}
+}
+
+func TestTransitionUnknownStatus(t *testing.T) {
+	if err := Transition("bogus", StatusPaid); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Transition(bogus, paid) = %v, want ErrInvalidTransition", err)
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	for _, status := range allStatuses {
+		if got, ok := ParseStatus(string(status)); !ok || got != status {
+			t.Errorf("ParseStatus(%q) = %q, %v", status, got, ok)
+		}
+	}
+
+	for _, s := range []string{"", "PAID", "unknown"} {
+		if _, ok := ParseStatus(s); ok {
+			t.Errorf("ParseStatus(%q) succeeded", s)
+		}
+	}
+}
+
+func TestStatusProtoRoundTrip(t *testing.T) {
+	for _, status := range allStatuses {
+		got, ok := StatusFromProto(status.ToProto())
+		if !ok || got != status {
+			t.Errorf("StatusFromProto(%s.ToProto()) = %q, %v", status, got, ok)
+		}
+	}
+
+	if _, ok := StatusFromProto(api.PaymentStatus_PAYMENT_STATUS_UNSPECIFIED); ok {
+		t.Error("StatusFromProto(UNSPECIFIED) succeeded")
+	}
+	if got := Status("bogus").ToProto(); got != api.PaymentStatus_PAYMENT_STATUS_UNSPECIFIED {
+		t.Errorf("ToProto() of an unknown status = %v, want UNSPECIFIED", got)
+	}
+}
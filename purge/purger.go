@@ -0,0 +1,82 @@
+package purge
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logs "github.com/daffaromero/gorpc-template/helper/logger"
+	"github.com/daffaromero/gorpc-template/repository"
+	"github.com/daffaromero/gorpc-template/repository/query"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Purger periodically hard-deletes users and orders that have been
+// soft-deleted for longer than the retention window.
+type Purger struct {
+	db         repository.Store
+	userQuery  query.UserQuery
+	orderQuery query.OrderQuery
+	retention  time.Duration
+	interval   time.Duration
+	logger     *logs.Log
+}
+
+func NewPurger(db repository.Store, userQuery query.UserQuery, orderQuery query.OrderQuery, retention, interval time.Duration) *Purger {
+	return &Purger{
+		db:         db,
+		userQuery:  userQuery,
+		orderQuery: orderQuery,
+		retention:  retention,
+		interval:   interval,
+		logger:     logs.New("purger"),
+	}
+}
+
+// Run purges on every tick until ctx is cancelled.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.purge(ctx); err != nil {
+			p.logger.Error("Failed to purge soft-deleted rows: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Purger) purge(ctx context.Context) error {
+	deletedBefore := time.Now().Add(-p.retention)
+	var orders, users int64
+
+	err := p.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		// Orders go first so that their users become purgeable in the same run.
+		orders, err = p.orderQuery.PurgeOrders(ctx, tx, deletedBefore)
+		if err != nil {
+			return fmt.Errorf("failed to purge orders: %w", err)
+		}
+
+		users, err = p.userQuery.PurgeUsers(ctx, tx, deletedBefore)
+		if err != nil {
+			return fmt.Errorf("failed to purge users: %w", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if orders > 0 || users > 0 {
+		p.logger.Info("Purged %d orders and %d users deleted before %s", orders, users, deletedBefore.Format(time.RFC3339))
+	}
+	return nil
+}
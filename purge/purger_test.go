@@ -0,0 +1,87 @@
+package purge
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/daffaromero/gorpc-template/repository"
+	"github.com/daffaromero/gorpc-template/repository/query"
+)
+
+// fakeStore runs fn once with a nil transaction.
+type fakeStore struct {
+	repository.Store
+}
+
+func (fakeStore) WithTx(ctx context.Context, fn func(ctx context.Context, tx pgx.Tx) error, opts ...repository.TxOption) error {
+	return fn(ctx, nil)
+}
+
+// purgeLog records the purges in the order they ran and their cutoffs.
+type purgeLog struct {
+	calls   []string
+	cutoffs []time.Time
+}
+
+type fakeUserQuery struct {
+	query.UserQuery
+	log *purgeLog
+	err error
+}
+
+func (q *fakeUserQuery) PurgeUsers(ctx context.Context, tx pgx.Tx, deletedBefore time.Time) (int64, error) {
+	q.log.calls = append(q.log.calls, "users")
+	q.log.cutoffs = append(q.log.cutoffs, deletedBefore)
+	return 1, q.err
+}
+
+type fakeOrderQuery struct {
+	query.OrderQuery
+	log *purgeLog
+	err error
+}
+
+func (q *fakeOrderQuery) PurgeOrders(ctx context.Context, tx pgx.Tx, deletedBefore time.Time) (int64, error) {
+	q.log.calls = append(q.log.calls, "orders")
+	q.log.cutoffs = append(q.log.cutoffs, deletedBefore)
+	return 2, q.err
+}
+
+func TestPurge(t *testing.T) {
+	log := &purgeLog{}
+	retention := 30 * 24 * time.Hour
+	purger := NewPurger(fakeStore{}, &fakeUserQuery{log: log}, &fakeOrderQuery{log: log}, retention, time.Hour)
+
+	before := time.Now()
+	if err := purger.purge(context.Background()); err != nil {
+		t.Fatalf("purge() = %v", err)
+	}
+	after := time.Now()
+
+	if !slices.Equal(log.calls, []string{"orders", "users"}) {
+		t.Errorf("purged %v, want orders before users", log.calls)
+	}
+	for _, cutoff := range log.cutoffs {
+		if cutoff.Before(before.Add(-retention)) || cutoff.After(after.Add(-retention)) {
+			t.Errorf("cutoff = %v, want %v ago", cutoff, retention)
+		}
+	}
+}
+
+func TestPurgeStopsOnError(t *testing.T) {
+	log := &purgeLog{}
+	errPurge := errors.New("purge failed")
+	purger := NewPurger(fakeStore{}, &fakeUserQuery{log: log}, &fakeOrderQuery{log: log, err: errPurge}, time.Hour, time.Hour)
+
+	if err := purger.purge(context.Background()); !errors.Is(err, errPurge) {
+		t.Errorf("purge() = %v, want %v", err, errPurge)
+	}
+	if !slices.Equal(log.calls, []string{"orders"}) {
+		t.Errorf("purged %v, want to stop after orders failed", log.calls)
+	}
+}
@@ -2,50 +2,186 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"expvar"
 	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/daffaromero/gorpc-template/config"
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
 	"github.com/daffaromero/gorpc-template/helper/logger"
+	"github.com/daffaromero/gorpc-template/repository/query"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// txMetrics is published at /debug/vars. "retries" counts transactions that
+// were run again after a serialization failure or deadlock, and "exhausted"
+// counts those that still failed after the last retry.
+var txMetrics = expvar.NewMap("transactions")
+
+// Store runs repository work against the database. The transaction opened
+// by WithTx travels in the context passed to fn, so repository methods called
+// with that context run inside it instead of opening their own. Reads made
+// inside WithoutTx join it too when the caller already has one, and otherwise
+// go to the read replica when one is configured and healthy.
 type Store interface {
-	WithTx(ctx context.Context, fn func(tx pgx.Tx) error) error
+	// WithTx runs fn in a transaction. When Postgres aborts the transaction
+	// with a serialization failure or a deadlock, fn is run again in a new
+	// transaction, so it must not have side effects outside the database
+	// unless NoRetry is given.
+	//
+	// When ctx already carries a transaction, fn runs in a savepoint of it
+	// instead. An error from fn then only undoes the writes made since the
+	// savepoint and is returned to the caller, which decides whether to carry
+	// on with its own transaction. Retries only apply to the outermost
+	// transaction. A savepoint cannot change the isolation level or access
+	// mode either, so a nested call that asks for a stricter level, or for
+	// read-only access, than the outer transaction has fails with
+	// ErrTxOptionsConflict.
+	WithTx(ctx context.Context, fn func(ctx context.Context, tx pgx.Tx) error, opts ...TxOption) error
+	// WithoutTx runs fn outside a transaction. fn must only read, since it
+	// may run on the replica, and run again on the primary if the replica
+	// cannot be reached, drops the connection while fn runs, or cancels a
+	// read that conflicts with replication.
 	WithoutTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
+// TxOption configures a transaction started by Store.WithTx.
+type TxOption func(*txSettings)
+
+type txSettings struct {
+	options pgx.TxOptions
+	noRetry bool
+}
+
+func Isolation(level pgx.TxIsoLevel) TxOption {
+	return func(s *txSettings) { s.options.IsoLevel = level }
+}
+
+func ReadOnly() TxOption {
+	return func(s *txSettings) { s.options.AccessMode = pgx.ReadOnly }
+}
+
+// Deferrable only has an effect on serializable read-only transactions,
+// which then wait for a snapshot that cannot cause serialization failures.
+func Deferrable() TxOption {
+	return func(s *txSettings) { s.options.DeferrableMode = pgx.Deferrable }
+}
+
+// NoRetry runs fn only once, for callbacks with side effects outside the
+// database. A serialization failure or deadlock is returned to the caller.
+func NoRetry() TxOption {
+	return func(s *txSettings) { s.noRetry = true }
+}
+
+// ErrTxOptionsConflict is returned by a nested WithTx call that asks for a
+// stricter isolation level, or for read-only access, when the outer
+// transaction was not started that way. A savepoint cannot change either.
+var ErrTxOptionsConflict = errors.New("transaction options conflict with the outer transaction")
+
+type (
+	primaryKey   struct{}
+	txOptionsKey struct{}
+)
+
+// ReadYourWrites returns a copy of ctx whose reads in WithoutTx go to the
+// primary even when a replica is healthy. Use it for reads that must see a
+// write that was just committed, which a replica may not have replayed yet.
+func ReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// beginner starts transactions. *pgxpool.Pool implements it.
+type beginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
 type store struct {
-	db     *pgxpool.Pool
-	config config.DBConfig
-	logger *logger.Log
+	db      beginner
+	replica *Replica
+	config  config.DBConfig
+	logger  *logger.Log
 }
 
-func NewStore(db *pgxpool.Pool, config config.DBConfig) Store {
-	return &store{db: db, config: config}
+// NewStore returns a Store for the primary db. replica may be nil.
+func NewStore(db *pgxpool.Pool, replica *Replica, config config.DBConfig) Store {
+	return &store{db: db, replica: replica, config: config, logger: logger.New("store")}
 }
 
-func (s *store) WithTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
-	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.config.TimeOutDuration)*time.Second)
+func (s *store) WithTx(ctx context.Context, fn func(ctx context.Context, tx pgx.Tx) error, opts ...TxOption) error {
+	var settings txSettings
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	txOptions := settings.options
+
+	// Only the outermost transaction is retried. Running fn again inside a
+	// snapshot that has already failed would just fail again.
+	if outer, ok := query.TxFromContext(ctx); ok {
+		outerOptions, _ := ctx.Value(txOptionsKey{}).(pgx.TxOptions)
+		if err := checkNested(outerOptions, txOptions); err != nil {
+			return err
+		}
+		return s.runTx(ctx, outer.Begin, fn)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.TimeOutDuration)
 	defer cancel()
+	ctx = context.WithValue(ctx, txOptionsKey{}, txOptions)
+
+	for attempt := 0; ; attempt++ {
+		err := s.runTx(ctx, func(ctx context.Context) (pgx.Tx, error) {
+			return s.db.BeginTx(ctx, txOptions)
+		}, fn)
+		if err == nil || settings.noRetry || !retryable(err) {
+			return err
+		}
 
-	tx, err := s.db.Begin(ctx)
+		if attempt == s.config.TxMaxRetries {
+			txMetrics.Add("exhausted", 1)
+			s.logger.Error("Transaction failed after %d retries: %v", attempt, err)
+			return err
+		}
+
+		delay := retryDelay(s.config.TxRetryBaseDelay, attempt)
+		txMetrics.Add("retries", 1)
+		s.logger.Warn("Retrying transaction in %v (retry %d of %d): %v", delay, attempt+1, s.config.TxMaxRetries, err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fmt.Errorf("transaction not retried: %w", errors.Join(ctx.Err(), err))
+		}
+	}
+}
+
+// runTx runs fn in the transaction returned by begin, which is either a new
+// transaction or a savepoint of the one in ctx. Committing a savepoint
+// releases it and rolling it back returns to it.
+func (s *store) runTx(ctx context.Context, begin func(ctx context.Context) (pgx.Tx, error), fn func(ctx context.Context, tx pgx.Tx) error) (err error) {
+	tx, err := begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	ctx = query.ContextWithTx(ctx, tx)
 
 	defer func() {
 		if err != nil {
 			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
-				s.logger.Error("Rollback error", "error", rollbackErr, "original_error", err)
+				s.logger.Error("Rollback error: %v (original error: %v)", rollbackErr, err)
 
 				err = fmt.Errorf("rollback error: %v (original error: %w)", rollbackErr, err)
 			}
 		}
 	}()
 
-	if err = fn(tx); err != nil {
+	if err = fn(ctx, tx); err != nil {
 		return fmt.Errorf("transaction function failed: %w", err)
 	}
 
@@ -60,9 +196,132 @@ func (s *store) WithoutTx(ctx context.Context, fn func(ctx context.Context) erro
 	ctx, cancel := context.WithTimeout(ctx, s.config.TimeOutDuration)
 	defer cancel()
 
-	if err := fn(ctx); err != nil {
+	if s.readFromReplica(ctx) {
+		err := fn(query.ContextWithReader(ctx, s.replica.db))
+		switch {
+		case err == nil:
+			return nil
+		case ctx.Err() == nil && replicaUnavailable(err):
+			// fn only reads, so it can run again on the primary.
+			s.replica.markUnhealthy(err)
+		case ctx.Err() == nil && recoveryConflict(err):
+			// The replica cancelled the read to replay WAL. It is healthy,
+			// so only this read moves to the primary.
+		default:
 			return fmt.Errorf("operation failed: %w", err)
+		}
+	}
+
+	if err := fn(ctx); err != nil {
+		return fmt.Errorf("operation failed: %w", err)
 	}
 
 	return nil
 }
+
+func (s *store) readFromReplica(ctx context.Context) bool {
+	if _, ok := query.TxFromContext(ctx); ok {
+		return false
+	}
+	if primary, _ := ctx.Value(primaryKey{}).(bool); primary {
+		return false
+	}
+	return s.replica.available()
+}
+
+// replicaUnavailable reports whether a read failed because the replica could
+// not be reached or went away while serving it, rather than because of the
+// read itself.
+func replicaUnavailable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Class 08 is connection exceptions and 57P shutdowns and restarts.
+		return strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "57P")
+	}
+
+	var domainErr *domainerr.Error
+	if errors.As(err, &domainErr) {
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, new(*pgconn.ConnectError)) ||
+		errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		pgconn.SafeToRetry(err)
+}
+
+// recoveryConflict reports whether a hot standby cancelled a read because it
+// conflicted with WAL replay. Postgres reports that as a serialization
+// failure.
+func recoveryConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "40001"
+}
+
+// checkNested returns ErrTxOptionsConflict when a savepoint asking for inner
+// cannot honor it inside a transaction started with outer.
+func checkNested(outer, inner pgx.TxOptions) error {
+	if isolationRank(inner.IsoLevel) > isolationRank(outer.IsoLevel) {
+		return fmt.Errorf("%w: %s requested inside %s", ErrTxOptionsConflict, isolationName(inner.IsoLevel), isolationName(outer.IsoLevel))
+	}
+	if inner.AccessMode == pgx.ReadOnly && outer.AccessMode != pgx.ReadOnly {
+		return fmt.Errorf("%w: read only requested inside a read write transaction", ErrTxOptionsConflict)
+	}
+	return nil
+}
+
+// isolationRank orders isolation levels from weakest to strictest. The empty
+// level is the server default, read committed.
+func isolationRank(level pgx.TxIsoLevel) int {
+	switch level {
+	case pgx.ReadUncommitted:
+		return 0
+	case pgx.RepeatableRead:
+		return 2
+	case pgx.Serializable:
+		return 3
+	default:
+		return 1
+	}
+}
+
+func isolationName(level pgx.TxIsoLevel) string {
+	if level == "" {
+		return string(pgx.ReadCommitted)
+	}
+	return string(level)
+}
+
+// retryable reports whether Postgres asks the client to run the transaction
+// again.
+func retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	switch pgErr.Code {
+	case "40001", "40P01": // serialization_failure, deadlock_detected
+		return true
+	default:
+		return false
+	}
+}
+
+// maxRetryDelay caps the delay before a retry, however many retries are
+// configured.
+const maxRetryDelay = time.Second
+
+// retryDelay doubles base for every attempt, up to maxRetryDelay, and picks a
+// random delay between half and all of that, so that transactions which
+// collided once do not collide again.
+func retryDelay(base time.Duration, attempt int) time.Duration {
+	// Comparing before shifting keeps large attempts from overflowing.
+	delay := maxRetryDelay
+	if base < maxRetryDelay>>attempt {
+		delay = base << attempt
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
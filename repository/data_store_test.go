@@ -0,0 +1,378 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/daffaromero/gorpc-template/config"
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/helper/logger"
+	"github.com/daffaromero/gorpc-template/repository/query"
+)
+
+// fakeTx records how it ended. Begin starts a savepoint, which is a fakeTx
+// of its own.
+type fakeTx struct {
+	pgx.Tx
+	savepoints []*fakeTx
+	commitErr  error
+	committed  bool
+	rolledBack bool
+}
+
+func (tx *fakeTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	savepoint := &fakeTx{}
+	tx.savepoints = append(tx.savepoints, savepoint)
+	return savepoint, nil
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	if tx.commitErr != nil {
+		return tx.commitErr
+	}
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	tx.rolledBack = true
+	return nil
+}
+
+// fakeDB starts a new fakeTx for every BeginTx and records the options. The
+// commit of the i-th transaction fails with commitErrs[i], if set.
+type fakeDB struct {
+	txs        []*fakeTx
+	options    []pgx.TxOptions
+	commitErrs []error
+}
+
+func (db *fakeDB) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	tx := &fakeTx{}
+	if i := len(db.txs); i < len(db.commitErrs) {
+		tx.commitErr = db.commitErrs[i]
+	}
+	db.txs = append(db.txs, tx)
+	db.options = append(db.options, txOptions)
+	return tx, nil
+}
+
+func newTestStore(db *fakeDB) *store {
+	return &store{
+		db: db,
+		config: config.DBConfig{
+			TimeOutDuration:  time.Second,
+			TxMaxRetries:     3,
+			TxRetryBaseDelay: time.Millisecond,
+		},
+		logger: logger.New("store"),
+	}
+}
+
+var (
+	errSerialization = &pgconn.PgError{Code: "40001"}
+	errDeadlock      = &pgconn.PgError{Code: "40P01"}
+)
+
+// failTimes returns an fn that fails with err on its first n runs.
+func failTimes(n int, err error) (func(ctx context.Context, tx pgx.Tx) error, *int) {
+	calls := 0
+	return func(ctx context.Context, tx pgx.Tx) error {
+		calls++
+		if calls <= n {
+			return err
+		}
+		return nil
+	}, &calls
+}
+
+func TestWithTxRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		err       error
+		opts      []TxOption
+		wantCalls int
+		wantErr   bool
+	}{
+		{"success", 0, nil, nil, 1, false},
+		{"serialization failure", 2, errSerialization, nil, 3, false},
+		{"deadlock", 1, errDeadlock, nil, 2, false},
+		{"classified by a query", 1, domainerr.ForResource(domainerr.Conflict, "item", "1", "was modified concurrently").WithCause(errSerialization), nil, 2, false},
+		{"retries exhausted", 10, errSerialization, nil, 4, true},
+		{"not retryable", 1, &pgconn.PgError{Code: "23505"}, nil, 1, true},
+		{"plain error", 1, errors.New("boom"), nil, 1, true},
+		{"no retry", 1, errSerialization, []TxOption{NoRetry()}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{}
+			fn, calls := failTimes(tt.failures, tt.err)
+
+			err := newTestStore(db).WithTx(context.Background(), fn, tt.opts...)
+
+			if *calls != tt.wantCalls {
+				t.Errorf("fn ran %d times, want %d", *calls, tt.wantCalls)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WithTx() = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, tt.err) {
+				t.Errorf("WithTx() = %v, want it to wrap %v", err, tt.err)
+			}
+
+			for i, tx := range db.txs {
+				last := i == len(db.txs)-1
+				if want := last && !tt.wantErr; tx.committed != want {
+					t.Errorf("transaction %d committed = %v, want %v", i, tx.committed, want)
+				}
+				if want := !last || tt.wantErr; tx.rolledBack != want {
+					t.Errorf("transaction %d rolled back = %v, want %v", i, tx.rolledBack, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithTxRetriesFailedCommit(t *testing.T) {
+	db := &fakeDB{commitErrs: []error{errSerialization}}
+	fn, calls := failTimes(0, nil)
+
+	if err := newTestStore(db).WithTx(context.Background(), fn); err != nil {
+		t.Fatalf("WithTx() = %v", err)
+	}
+
+	if *calls != 2 || len(db.txs) != 2 || !db.txs[1].committed {
+		t.Errorf("fn ran %d times in %d transactions, want a second committed run", *calls, len(db.txs))
+	}
+}
+
+func TestWithTxOptions(t *testing.T) {
+	db := &fakeDB{}
+	fn, _ := failTimes(1, errSerialization)
+
+	err := newTestStore(db).WithTx(context.Background(), fn, Isolation(pgx.Serializable), ReadOnly(), Deferrable())
+	if err != nil {
+		t.Fatalf("WithTx() = %v", err)
+	}
+
+	want := pgx.TxOptions{IsoLevel: pgx.Serializable, AccessMode: pgx.ReadOnly, DeferrableMode: pgx.Deferrable}
+	for i, options := range db.options {
+		if options != want {
+			t.Errorf("transaction %d options = %+v, want %+v", i, options, want)
+		}
+	}
+}
+
+func TestWithTxContext(t *testing.T) {
+	db := &fakeDB{}
+
+	err := newTestStore(db).WithTx(context.Background(), func(ctx context.Context, tx pgx.Tx) error {
+		if got, ok := query.TxFromContext(ctx); !ok || got != tx {
+			t.Errorf("ctx carries %v, want the transaction %v", got, tx)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx() = %v", err)
+	}
+}
+
+func TestWithTxCancelledWhileWaiting(t *testing.T) {
+	s := newTestStore(&fakeDB{})
+	s.config.TxRetryBaseDelay = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	err := s.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		calls++
+		cancel()
+		return errSerialization
+	})
+
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, errSerialization) {
+		t.Errorf("WithTx() = %v, want both the cancellation and the serialization failure", err)
+	}
+	if calls != 1 {
+		t.Errorf("fn ran %d times, want 1", calls)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    time.Duration
+		attempt int
+		want    time.Duration
+	}{
+		{"first retry", 20 * time.Millisecond, 0, 20 * time.Millisecond},
+		{"doubles", 20 * time.Millisecond, 3, 160 * time.Millisecond},
+		{"capped", 20 * time.Millisecond, 6, maxRetryDelay},
+		{"base above the cap", time.Minute, 0, maxRetryDelay},
+		{"shift would overflow", 20 * time.Millisecond, 62, maxRetryDelay},
+		{"shift past the width", 20 * time.Millisecond, 100, maxRetryDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if delay := retryDelay(tt.base, tt.attempt); delay < tt.want/2 || delay > tt.want {
+					t.Fatalf("retryDelay(%v, %d) = %v, want between %v and %v", tt.base, tt.attempt, delay, tt.want/2, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckNested(t *testing.T) {
+	readOnly := pgx.TxOptions{AccessMode: pgx.ReadOnly}
+	serializable := pgx.TxOptions{IsoLevel: pgx.Serializable}
+
+	tests := []struct {
+		name         string
+		outer, inner pgx.TxOptions
+		wantErr      bool
+	}{
+		{"defaults", pgx.TxOptions{}, pgx.TxOptions{}, false},
+		{"same level", serializable, serializable, false},
+		{"weaker level", serializable, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, false},
+		{"default inside serializable", serializable, pgx.TxOptions{}, false},
+		{"read committed is the default", pgx.TxOptions{}, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, false},
+		{"stricter level", pgx.TxOptions{}, serializable, true},
+		{"repeatable read inside read committed", pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, pgx.TxOptions{IsoLevel: pgx.RepeatableRead}, true},
+		{"read only inside read only", readOnly, readOnly, false},
+		{"read write inside read only", readOnly, pgx.TxOptions{}, false},
+		{"read only inside read write", pgx.TxOptions{}, readOnly, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkNested(tt.outer, tt.inner)
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrTxOptionsConflict)) {
+				t.Errorf("checkNested() = %v, want ErrTxOptionsConflict %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithTxNestedOptionsConflict(t *testing.T) {
+	db := &fakeDB{}
+	s := newTestStore(db)
+
+	var nestedErr error
+	err := s.WithTx(context.Background(), func(ctx context.Context, tx pgx.Tx) error {
+		nestedErr = s.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+			t.Error("nested fn ran despite conflicting options")
+			return nil
+		}, Isolation(pgx.Serializable))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx() = %v", err)
+	}
+
+	if !errors.Is(nestedErr, ErrTxOptionsConflict) {
+		t.Errorf("nested WithTx() = %v, want ErrTxOptionsConflict", nestedErr)
+	}
+	if len(db.txs[0].savepoints) != 0 {
+		t.Errorf("started %d savepoints, want none", len(db.txs[0].savepoints))
+	}
+}
+
+func TestWithTxSavepoint(t *testing.T) {
+	errInner := errors.New("item out of stock")
+
+	tests := []struct {
+		name          string
+		innerErr      error
+		outerKeepsErr bool
+		wantCommitted bool
+		wantReleased  bool
+	}{
+		{"inner succeeds", nil, false, true, true},
+		{"outer carries on after inner fails", errInner, false, true, false},
+		{"outer fails with inner", errInner, true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{}
+			s := newTestStore(db)
+
+			var innerTx pgx.Tx
+			var innerCalls int
+			err := s.WithTx(context.Background(), func(ctx context.Context, outer pgx.Tx) error {
+				err := s.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+					innerCalls++
+					innerTx, _ = query.TxFromContext(ctx)
+					if tx == outer {
+						t.Error("nested fn runs in the outer transaction, not a savepoint")
+					}
+					return tt.innerErr
+				})
+				if !errors.Is(err, tt.innerErr) {
+					t.Errorf("nested WithTx() = %v, want %v", err, tt.innerErr)
+				}
+				if tt.outerKeepsErr {
+					return err
+				}
+				return nil
+			})
+			if (err != nil) != tt.outerKeepsErr {
+				t.Fatalf("WithTx() = %v", err)
+			}
+
+			if len(db.txs) != 1 || len(db.txs[0].savepoints) != 1 {
+				t.Fatalf("started %d transactions, want one with one savepoint", len(db.txs))
+			}
+			outer, savepoint := db.txs[0], db.txs[0].savepoints[0]
+			if innerTx != savepoint {
+				t.Errorf("nested ctx carries %v, want the savepoint", innerTx)
+			}
+			if innerCalls != 1 {
+				t.Errorf("nested fn ran %d times, want 1", innerCalls)
+			}
+			if savepoint.committed != tt.wantReleased || savepoint.rolledBack == tt.wantReleased {
+				t.Errorf("savepoint released = %v, rolled back = %v, want released %v", savepoint.committed, savepoint.rolledBack, tt.wantReleased)
+			}
+			if outer.committed != tt.wantCommitted {
+				t.Errorf("outer committed = %v, want %v", outer.committed, tt.wantCommitted)
+			}
+		})
+	}
+}
+
+func TestWithTxSavepointNotRetried(t *testing.T) {
+	db := &fakeDB{}
+	s := newTestStore(db)
+
+	innerCalls := 0
+	err := s.WithTx(context.Background(), func(ctx context.Context, tx pgx.Tx) error {
+		return s.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+			innerCalls++
+			if innerCalls == 1 {
+				return errSerialization
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("WithTx() = %v", err)
+	}
+
+	// The failure is retried once, by running the outer transaction again.
+	if innerCalls != 2 || len(db.txs) != 2 {
+		t.Errorf("nested fn ran %d times in %d transactions, want twice in two", innerCalls, len(db.txs))
+	}
+	if len(db.txs[0].savepoints) != 1 || !db.txs[0].rolledBack || !db.txs[1].committed {
+		t.Error("the first transaction was not rolled back as a whole")
+	}
+}
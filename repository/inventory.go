@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository/query"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// appendLowStockEvents appends an ItemLowStock event for every change that
+// takes an item from above threshold to at or below it. Changes that stay
+// below the threshold do not repeat the event.
+func appendLowStockEvents(ctx context.Context, tx pgx.Tx, outboxQuery query.OutboxQuery, threshold int32, changes ...*query.StockChange) error {
+	for _, change := range changes {
+		if change.Before <= threshold || change.After > threshold {
+			continue
+		}
+
+		err := appendEvent(ctx, tx, outboxQuery, itemAggregate, change.ItemID, &api.ItemLowStock{
+			ItemId:    change.ItemID,
+			Stock:     change.After,
+			Threshold: threshold,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
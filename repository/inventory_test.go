@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository/query"
+)
+
+func TestAppendLowStockEvents(t *testing.T) {
+	const threshold = 5
+
+	tests := []struct {
+		name          string
+		before, after int32
+		want          bool
+	}{
+		{"stays above", 20, 10, false},
+		{"drops to threshold", 6, 5, true},
+		{"drops below threshold", 10, 0, true},
+		{"already low", 5, 3, false},
+		{"restocked", 3, 10, false},
+		{"restocked to threshold", 3, 5, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outboxQuery := &fakeOutboxQuery{}
+			change := &query.StockChange{ItemID: "item-1", Before: tt.before, After: tt.after}
+
+			if err := appendLowStockEvents(context.Background(), nil, outboxQuery, threshold, change); err != nil {
+				t.Fatalf("appendLowStockEvents() = %v", err)
+			}
+
+			if got := len(outboxQuery.events) == 1; got != tt.want {
+				t.Fatalf("appended %d events, want event %v", len(outboxQuery.events), tt.want)
+			}
+			if !tt.want {
+				return
+			}
+
+			var event api.ItemLowStock
+			if err := proto.Unmarshal(outboxQuery.events[0].Payload, &event); err != nil {
+				t.Fatalf("proto.Unmarshal() = %v", err)
+			}
+			if event.ItemId != "item-1" || event.Stock != tt.after || event.Threshold != threshold {
+				t.Errorf("event = %v", &event)
+			}
+		})
+	}
+}
@@ -13,30 +13,41 @@ import (
 type ItemRepository interface {
 	CreateItem(ctx context.Context, item *api.Item) (*api.Item, error)
 	GetItem(ctx context.Context, id string) (*api.Item, error)
-	ListItems(ctx context.Context) ([]*api.Item, error)
+	ListItems(ctx context.Context, page query.Page) ([]*api.Item, int32, *query.Cursor, error)
 	UpdateItem(ctx context.Context, item *api.Item) (*api.Item, error)
 	DeleteItem(ctx context.Context, id string) error
+	AdjustStock(ctx context.Context, id string, delta int32) (*api.Item, error)
 }
 
 type itemRepository struct {
-	db        Store
-	itemQuery query.ItemQuery
+	db                Store
+	itemQuery         query.ItemQuery
+	inventoryQuery    query.InventoryQuery
+	outboxQuery       query.OutboxQuery
+	lowStockThreshold int32
 }
 
-func NewItemRepository(db Store, itemQuery query.ItemQuery) ItemRepository {
-	return &itemRepository{db: db, itemQuery: itemQuery}
+func NewItemRepository(db Store, itemQuery query.ItemQuery, inventoryQuery query.InventoryQuery, outboxQuery query.OutboxQuery, lowStockThreshold int32) ItemRepository {
+	return &itemRepository{
+		db:                db,
+		itemQuery:         itemQuery,
+		inventoryQuery:    inventoryQuery,
+		outboxQuery:       outboxQuery,
+		lowStockThreshold: lowStockThreshold,
+	}
 }
 
 func (r *itemRepository) CreateItem(ctx context.Context, item *api.Item) (*api.Item, error) {
 	var createdItem *api.Item
 
-	err := r.db.WithTx(ctx, func(tx pgx.Tx) error {
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
 		var err error
 		createdItem, err = r.itemQuery.CreateItem(ctx, tx, item)
 		if err != nil {
 			return fmt.Errorf("failed to create item: %w", err)
 		}
-		return nil
+
+		return appendEvent(ctx, tx, r.outboxQuery, itemAggregate, createdItem.Id, &api.ItemCreated{Item: createdItem})
 	})
 
 	if err != nil {
@@ -62,31 +73,41 @@ func (r *itemRepository) GetItem(ctx context.Context, id string) (*api.Item, err
 	return item, nil
 }
 
-func (r *itemRepository) ListItems(ctx context.Context) ([]*api.Item, error) {
+// ListItems returns a page of items. The total count is only computed for
+// offset pages; keyset pages skip it to stay cheap on large tables.
+func (r *itemRepository) ListItems(ctx context.Context, page query.Page) ([]*api.Item, int32, *query.Cursor, error) {
 	var items []*api.Item
+	var totalCount int32
+	var next *query.Cursor
 
 	err := r.db.WithoutTx(ctx, func(ctx context.Context) error {
 		var err error
-		items, err = r.itemQuery.ListItems(ctx)
+		items, next, err = r.itemQuery.ListItems(ctx, page)
+		if err != nil || page.After != nil {
+			return err
+		}
+
+		totalCount, err = r.itemQuery.CountItems(ctx)
 		return err
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to list items: %w", err)
+		return nil, 0, nil, fmt.Errorf("failed to list items: %w", err)
 	}
-	return items, nil
+	return items, totalCount, next, nil
 }
 
 func (r *itemRepository) UpdateItem(ctx context.Context, item *api.Item) (*api.Item, error) {
 	var updatedItem *api.Item
 
-	err := r.db.WithTx(ctx, func(tx pgx.Tx) error {
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
 		var err error
 		updatedItem, err = r.itemQuery.UpdateItem(ctx, tx, item)
 		if err != nil {
 			return fmt.Errorf("failed to update item: %w", err)
 		}
-		return nil
+
+		return appendEvent(ctx, tx, r.outboxQuery, itemAggregate, updatedItem.Id, &api.ItemUpdated{Item: updatedItem})
 	})
 
 	if err != nil {
@@ -97,12 +118,13 @@ func (r *itemRepository) UpdateItem(ctx context.Context, item *api.Item) (*api.I
 }
 
 func (r *itemRepository) DeleteItem(ctx context.Context, id string) error {
-	err := r.db.WithTx(ctx, func(tx pgx.Tx) error {
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
 		err := r.itemQuery.DeleteItem(ctx, tx, id)
 		if err != nil {
 			return fmt.Errorf("failed to delete item: %w", err)
 		}
-		return nil
+
+		return appendEvent(ctx, tx, r.outboxQuery, itemAggregate, id, &api.ItemDeleted{Id: id})
 	})
 
 	if err != nil {
@@ -111,3 +133,20 @@ func (r *itemRepository) DeleteItem(ctx context.Context, id string) error {
 
 	return nil
 }
+
+func (r *itemRepository) AdjustStock(ctx context.Context, id string, delta int32) (*api.Item, error) {
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		change, err := r.inventoryQuery.AdjustStock(ctx, tx, id, delta)
+		if err != nil {
+			return fmt.Errorf("failed to adjust stock: %w", err)
+		}
+
+		return appendLowStockEvents(ctx, tx, r.outboxQuery, r.lowStockThreshold, change)
+	}, Isolation(pgx.Serializable))
+
+	if err != nil {
+		return nil, fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return r.GetItem(ReadYourWrites(ctx), id)
+}
@@ -2,28 +2,354 @@ package repository
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/helper/money"
+	"github.com/daffaromero/gorpc-template/payment"
 	"github.com/daffaromero/gorpc-template/protobuf/api"
 	"github.com/daffaromero/gorpc-template/repository/query"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type OrderRepository interface {
 	CreateOrder(ctx context.Context, order *api.Order) (*api.Order, error)
 	GetOrder(ctx context.Context, id string) (*api.Order, error)
-	ListOrders(ctx context.Context) ([]*api.Order, error)
+	ListOrders(ctx context.Context, page query.Page, showDeleted bool) ([]*api.Order, int32, *query.Cursor, error)
 	UpdateOrder(ctx context.Context, order *api.Order) (*api.Order, error)
 	DeleteOrder(ctx context.Context, id string) error
+	RestoreOrder(ctx context.Context, id string) (*api.Order, error)
+	SetPaymentDetails(ctx context.Context, orderID string, details *payment.Payment) (*api.Order, error)
+	UpdatePaymentStatus(ctx context.Context, orderID, token string, status payment.Status) (*api.Order, error)
+	ApplyPaymentNotification(ctx context.Context, eventID, orderID, token string, status payment.Status) (bool, error)
 }
 
+// orderRepository runs every write that moves stock or payment state in a
+// serializable transaction. Store retries those that lose a race.
 type orderRepository struct {
-	db         Store
-	orderQuery query.OrderQuery
+	db                  Store
+	orderQuery          query.OrderQuery
+	inventoryQuery      query.InventoryQuery
+	outboxQuery         query.OutboxQuery
+	paymentWebhookQuery query.PaymentWebhookQuery
+	lowStockThreshold   int32
 }
 
-func NewOrderRepository(db Store, orderQuery query.OrderQuery) OrderRepository {
-	return &orderRepository{db: db, orderQuery: orderQuery}
+func NewOrderRepository(db Store, orderQuery query.OrderQuery, inventoryQuery query.InventoryQuery, outboxQuery query.OutboxQuery, paymentWebhookQuery query.PaymentWebhookQuery, lowStockThreshold int32) OrderRepository {
+	return &orderRepository{
+		db:                  db,
+		orderQuery:          orderQuery,
+		inventoryQuery:      inventoryQuery,
+		outboxQuery:         outboxQuery,
+		paymentWebhookQuery: paymentWebhookQuery,
+		lowStockThreshold:   lowStockThreshold,
+	}
 }
 
 func (r *orderRepository) CreateOrder(ctx context.Context, order *api.Order) (*api.Order, error) {
+	var createdOrder *api.Order
+
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		createdOrder, err = r.orderQuery.CreateOrder(ctx, tx, order)
+		if err != nil {
+			return fmt.Errorf("failed to create order: %w", err)
+		}
+
+		if err = r.orderQuery.CreateOrderItems(ctx, tx, createdOrder.Id, order.Items); err != nil {
+			return fmt.Errorf("failed to create order items: %w", err)
+		}
+
+		createdOrder.TotalPrice, err = r.updateOrderTotal(ctx, tx, createdOrder.Id)
+		if err != nil {
+			return err
+		}
+		createdOrder.Items = order.Items
+
+		if err = r.reserveStock(ctx, tx, createdOrder.Id); err != nil {
+			return err
+		}
+
+		return appendEvent(ctx, tx, r.outboxQuery, orderAggregate, createdOrder.Id, &api.OrderCreated{Order: createdOrder})
+	}, Isolation(pgx.Serializable))
+
+	if err != nil {
+		return nil, fmt.Errorf("transaction failed: %w", err)
+	}
+
+	// Read the order back from the primary so the response carries the joined
+	// item details.
+	return r.GetOrder(ReadYourWrites(ctx), createdOrder.Id)
+}
+
+func (r *orderRepository) GetOrder(ctx context.Context, id string) (*api.Order, error) {
+	var order *api.Order
+
+	err := r.db.WithoutTx(ctx, func(ctx context.Context) error {
+		var err error
+		order, err = r.orderQuery.GetOrder(ctx, id)
+		return err
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	return order, nil
+}
+
+// ListOrders returns a page of orders. The total count is only computed for
+// offset pages; keyset pages skip it to stay cheap on large tables.
+func (r *orderRepository) ListOrders(ctx context.Context, page query.Page, showDeleted bool) ([]*api.Order, int32, *query.Cursor, error) {
+	var orders []*api.Order
+	var totalCount int32
+	var next *query.Cursor
+
+	err := r.db.WithoutTx(ctx, func(ctx context.Context) error {
+		var err error
+		orders, next, err = r.orderQuery.ListOrders(ctx, page, showDeleted)
+		if err != nil || page.After != nil {
+			return err
+		}
+
+		totalCount, err = r.orderQuery.CountOrders(ctx, showDeleted)
+		return err
+	})
+
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+	return orders, totalCount, next, nil
+}
+
+func (r *orderRepository) UpdateOrder(ctx context.Context, order *api.Order) (*api.Order, error) {
+	var updatedOrder *api.Order
+
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		updatedOrder, err = r.orderQuery.UpdateOrder(ctx, tx, order)
+		if err != nil {
+			return fmt.Errorf("failed to update order: %w", err)
+		}
+
+		// Line items of a paid or cancelled order are final.
+		paymentStatus, _, err := r.orderQuery.GetPaymentForUpdate(ctx, tx, updatedOrder.Id)
+		if err != nil {
+			return fmt.Errorf("failed to get order payment: %w", err)
+		}
+		if paymentStatus != payment.StatusPending {
+			return domainerr.ForResource(domainerr.FailedPrecondition, "order", updatedOrder.Id, "can no longer be changed").WithCause(payment.ErrNotPending)
+		}
+
+		if err = r.inventoryQuery.ReleaseReservations(ctx, tx, updatedOrder.Id); err != nil {
+			return fmt.Errorf("failed to release stock: %w", err)
+		}
+
+		if err = r.orderQuery.DeleteOrderItems(ctx, tx, updatedOrder.Id); err != nil {
+			return fmt.Errorf("failed to delete order items: %w", err)
+		}
+
+		if err = r.orderQuery.CreateOrderItems(ctx, tx, updatedOrder.Id, order.Items); err != nil {
+			return fmt.Errorf("failed to create order items: %w", err)
+		}
+
+		updatedOrder.TotalPrice, err = r.updateOrderTotal(ctx, tx, updatedOrder.Id)
+		if err != nil {
+			return err
+		}
+		updatedOrder.Items = order.Items
+
+		if err = r.reserveStock(ctx, tx, updatedOrder.Id); err != nil {
+			return err
+		}
+
+		return appendEvent(ctx, tx, r.outboxQuery, orderAggregate, updatedOrder.Id, &api.OrderUpdated{Order: updatedOrder})
+	}, Isolation(pgx.Serializable))
+
+	if err != nil {
+		return nil, fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return r.GetOrder(ReadYourWrites(ctx), updatedOrder.Id)
+}
+
+func (r *orderRepository) DeleteOrder(ctx context.Context, id string) error {
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		err := r.orderQuery.DeleteOrder(ctx, tx, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete order: %w", err)
+		}
+
+		if err = r.inventoryQuery.ReleaseReservations(ctx, tx, id); err != nil {
+			return fmt.Errorf("failed to release stock: %w", err)
+		}
+
+		return appendEvent(ctx, tx, r.outboxQuery, orderAggregate, id, &api.OrderDeleted{Id: id})
+	}, Isolation(pgx.Serializable))
+
+	if err != nil {
+		return fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *orderRepository) RestoreOrder(ctx context.Context, id string) (*api.Order, error) {
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		restoredOrder, err := r.orderQuery.RestoreOrder(ctx, tx, id)
+		if err != nil {
+			return fmt.Errorf("failed to restore order: %w", err)
+		}
+
+		// Deleting the order released its stock; take it again if the order
+		// can still be paid.
+		if restoredOrder.PaymentStatus == payment.StatusPending.ToProto() {
+			if err = r.reserveStock(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+
+		return appendEvent(ctx, tx, r.outboxQuery, orderAggregate, id, &api.OrderRestored{Order: restoredOrder})
+	}, Isolation(pgx.Serializable))
+
+	if err != nil {
+		return nil, fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return r.GetOrder(ReadYourWrites(ctx), id)
+}
+
+func (r *orderRepository) SetPaymentDetails(ctx context.Context, orderID string, details *payment.Payment) (*api.Order, error) {
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		if err := r.orderQuery.SetPaymentDetails(ctx, tx, orderID, details); err != nil {
+			return fmt.Errorf("failed to set payment details: %w", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return r.GetOrder(ReadYourWrites(ctx), orderID)
+}
+
+// UpdatePaymentStatus moves the payment of an order to status. A non-empty
+// token must match the one issued by the provider. Repeating the current
+// status is a no-op so that redelivered callbacks succeed.
+func (r *orderRepository) UpdatePaymentStatus(ctx context.Context, orderID, token string, status payment.Status) (*api.Order, error) {
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		return r.updatePaymentStatus(ctx, tx, orderID, token, status)
+	}, Isolation(pgx.Serializable))
+
+	if err != nil {
+		return nil, fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return r.GetOrder(ReadYourWrites(ctx), orderID)
+}
+
+// ApplyPaymentNotification is UpdatePaymentStatus for webhook notifications.
+// The notification ID is recorded in the same transaction, and it returns
+// false without touching the order when the ID was seen before.
+func (r *orderRepository) ApplyPaymentNotification(ctx context.Context, eventID, orderID, token string, status payment.Status) (bool, error) {
+	var applied bool
+
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		applied, err = r.paymentWebhookQuery.RecordWebhookEvent(ctx, tx, eventID, orderID, string(status))
+		if err != nil {
+			return fmt.Errorf("failed to record webhook event: %w", err)
+		}
+		if !applied {
+			return nil
+		}
+
+		return r.updatePaymentStatus(ctx, tx, orderID, token, status)
+	}, Isolation(pgx.Serializable))
+
+	if err != nil {
+		return false, fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return applied, nil
+}
+
+func (r *orderRepository) reserveStock(ctx context.Context, tx pgx.Tx, orderID string) error {
+	changes, err := r.inventoryQuery.ReserveStock(ctx, tx, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to reserve stock: %w", err)
+	}
+
+	return appendLowStockEvents(ctx, tx, r.outboxQuery, r.lowStockThreshold, changes...)
+}
+
+// updateOrderTotal recomputes the total of an order from the current item
+// prices, so the total is never taken from the client. Orders whose items
+// are priced in different currencies are rejected.
+func (r *orderRepository) updateOrderTotal(ctx context.Context, tx pgx.Tx, orderID string) (*api.Money, error) {
+	lines, err := r.orderQuery.ListLinePrices(ctx, tx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order item prices: %w", err)
+	}
+
+	subtotals := make([]*api.Money, 0, len(lines))
+	for _, line := range lines {
+		subtotal, err := money.Multiply(line.Price, int64(line.Quantity))
+		if err != nil {
+			return nil, domainerr.Invalid("items", "item %s cannot be priced: %v", line.ItemID, err).WithCause(err)
+		}
+		subtotals = append(subtotals, subtotal)
+	}
+
+	total, err := money.Sum(subtotals...)
+	if err != nil {
+		return nil, domainerr.Invalid("items", "%v", err).WithCause(err)
+	}
+
+	if err := r.orderQuery.SetOrderTotal(ctx, tx, orderID, total); err != nil {
+		return nil, fmt.Errorf("failed to set order total: %w", err)
+	}
+
+	return total, nil
+}
+
+func (r *orderRepository) updatePaymentStatus(ctx context.Context, tx pgx.Tx, orderID, token string, status payment.Status) error {
+	current, currentToken, err := r.orderQuery.GetPaymentForUpdate(ctx, tx, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to get order payment: %w", err)
+	}
+
+	if token != "" && token != currentToken {
+		return domainerr.ForResource(domainerr.PermissionDenied, "order", orderID, "was issued a different payment token").WithCause(payment.ErrTokenMismatch)
+	}
+
+	if current == status {
+		return nil
+	}
+
+	if err = payment.Transition(current, status); err != nil {
+		return domainerr.ForResource(domainerr.FailedPrecondition, "order", orderID, fmt.Sprintf("cannot move payment from %s to %s", current, status)).WithCause(err)
+	}
+
+	if err = r.orderQuery.UpdatePaymentStatus(ctx, tx, orderID, status); err != nil {
+		return fmt.Errorf("failed to update payment status: %w", err)
+	}
+
+	switch status {
+	case payment.StatusPaid:
+		err = r.inventoryQuery.CommitReservations(ctx, tx, orderID)
+	case payment.StatusFailed, payment.StatusExpired:
+		err = r.inventoryQuery.ReleaseReservations(ctx, tx, orderID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to settle stock reservations: %w", err)
+	}
 
+	return appendEvent(ctx, tx, r.outboxQuery, orderAggregate, orderID, &api.OrderPaymentStatusChanged{
+		OrderId: orderID,
+		From:    current.ToProto(),
+		To:      status.ToProto(),
+	})
 }
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/helper/money"
+	"github.com/daffaromero/gorpc-template/payment"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository/query"
+)
+
+// fakeOrderQuery keeps a single order with its line items, payment status
+// and token, and records the statements run against it. ListLinePrices
+// returns lines and SetOrderTotal records the total.
+type fakeOrderQuery struct {
+	query.OrderQuery
+	order  *api.Order
+	items  []*api.OrderItem
+	lines  []*query.LinePrice
+	total  *api.Money
+	status payment.Status
+	token  string
+	calls  []string
+}
+
+func (q *fakeOrderQuery) CreateOrder(ctx context.Context, tx pgx.Tx, order *api.Order) (*api.Order, error) {
+	q.calls = append(q.calls, "create")
+	q.order = &api.Order{Id: order.Id, UserId: order.UserId}
+	return q.order, nil
+}
+
+func (q *fakeOrderQuery) UpdateOrder(ctx context.Context, tx pgx.Tx, order *api.Order) (*api.Order, error) {
+	q.calls = append(q.calls, "update")
+	q.order.UserId = order.UserId
+	return q.order, nil
+}
+
+func (q *fakeOrderQuery) CreateOrderItems(ctx context.Context, tx pgx.Tx, orderID string, items []*api.OrderItem) error {
+	q.calls = append(q.calls, "create items")
+	q.items = append(q.items, items...)
+	return nil
+}
+
+func (q *fakeOrderQuery) DeleteOrderItems(ctx context.Context, tx pgx.Tx, orderID string) error {
+	q.calls = append(q.calls, "delete items")
+	q.items = nil
+	return nil
+}
+
+func (q *fakeOrderQuery) ListLinePrices(ctx context.Context, tx pgx.Tx, orderID string) ([]*query.LinePrice, error) {
+	return q.lines, nil
+}
+
+func (q *fakeOrderQuery) SetOrderTotal(ctx context.Context, tx pgx.Tx, orderID string, total *api.Money) error {
+	q.calls = append(q.calls, "total")
+	q.total = total
+	return nil
+}
+
+func (q *fakeOrderQuery) GetOrder(ctx context.Context, id string) (*api.Order, error) {
+	order := proto.Clone(q.order).(*api.Order)
+	order.Items = q.items
+	order.TotalPrice = q.total
+	return order, nil
+}
+
+func (q *fakeOrderQuery) GetPaymentForUpdate(ctx context.Context, tx pgx.Tx, orderID string) (payment.Status, string, error) {
+	return q.status, q.token, nil
+}
+
+func (q *fakeOrderQuery) UpdatePaymentStatus(ctx context.Context, tx pgx.Tx, orderID string, status payment.Status) error {
+	q.status = status
+	return nil
+}
+
+// fakeInventoryQuery records which reservations were made, committed or
+// released.
+type fakeInventoryQuery struct {
+	query.InventoryQuery
+	calls []string
+}
+
+func (q *fakeInventoryQuery) ReserveStock(ctx context.Context, tx pgx.Tx, orderID string) ([]*query.StockChange, error) {
+	q.calls = append(q.calls, "reserve")
+	return nil, nil
+}
+
+func (q *fakeInventoryQuery) CommitReservations(ctx context.Context, tx pgx.Tx, orderID string) error {
+	q.calls = append(q.calls, "commit")
+	return nil
+}
+
+func (q *fakeInventoryQuery) ReleaseReservations(ctx context.Context, tx pgx.Tx, orderID string) error {
+	q.calls = append(q.calls, "release")
+	return nil
+}
+
+func TestCreateOrder(t *testing.T) {
+	orderQuery := &fakeOrderQuery{lines: []*query.LinePrice{{ItemID: "item-1", Price: money.New("IDR", 15000), Quantity: 2}}}
+	inventoryQuery := &fakeInventoryQuery{}
+	outboxQuery := &fakeOutboxQuery{}
+	r := NewOrderRepository(&recordingStore{}, orderQuery, inventoryQuery, outboxQuery, nil, 5)
+
+	order := &api.Order{
+		Id:         "order-1",
+		UserId:     "user-1",
+		Items:      []*api.OrderItem{{Item: &api.Item{Id: "item-1"}, Quantity: 2}},
+		TotalPrice: money.New("IDR", 1),
+	}
+	created, err := r.CreateOrder(context.Background(), order)
+	if err != nil {
+		t.Fatalf("CreateOrder() = %v", err)
+	}
+
+	// The total is recomputed from the stored line items, never taken from
+	// the request.
+	if want := []string{"create", "create items", "total"}; !slices.Equal(orderQuery.calls, want) {
+		t.Errorf("calls = %v, want %v", orderQuery.calls, want)
+	}
+	if created.GetTotalPrice().GetMinorUnits() != 30000 {
+		t.Errorf("total = %v, want IDR 30000 computed from item prices", created.GetTotalPrice())
+	}
+	if !slices.Equal(inventoryQuery.calls, []string{"reserve"}) {
+		t.Errorf("reservations %v, want stock reserved", inventoryQuery.calls)
+	}
+	if len(created.GetItems()) != 1 || created.GetItems()[0].GetItem().GetId() != "item-1" || created.GetItems()[0].GetQuantity() != 2 {
+		t.Errorf("items = %v, want the line items of the request", created.GetItems())
+	}
+	if len(outboxQuery.events) != 1 || outboxQuery.events[0].EventType != "OrderCreated" {
+		t.Errorf("events = %v, want OrderCreated", outboxQuery.events)
+	}
+}
+
+func TestUpdateOrderReplacesItems(t *testing.T) {
+	orderQuery := &fakeOrderQuery{
+		order:  &api.Order{Id: "order-1", UserId: "user-1"},
+		items:  []*api.OrderItem{{Item: &api.Item{Id: "item-1"}, Quantity: 1}},
+		lines:  []*query.LinePrice{{ItemID: "item-2", Price: money.New("IDR", 2500), Quantity: 3}},
+		status: payment.StatusPending,
+	}
+	inventoryQuery := &fakeInventoryQuery{}
+	r := NewOrderRepository(&recordingStore{}, orderQuery, inventoryQuery, &fakeOutboxQuery{}, nil, 5)
+
+	updated, err := r.UpdateOrder(context.Background(), &api.Order{
+		Id:     "order-1",
+		UserId: "user-1",
+		Items:  []*api.OrderItem{{Item: &api.Item{Id: "item-2"}, Quantity: 3}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateOrder() = %v", err)
+	}
+
+	if want := []string{"update", "delete items", "create items", "total"}; !slices.Equal(orderQuery.calls, want) {
+		t.Errorf("calls = %v, want %v", orderQuery.calls, want)
+	}
+	if len(updated.GetItems()) != 1 || updated.GetItems()[0].GetItem().GetId() != "item-2" {
+		t.Errorf("items = %v, want only item-2", updated.GetItems())
+	}
+	// The old lines give their stock back before the new ones take it.
+	if want := []string{"release", "reserve"}; !slices.Equal(inventoryQuery.calls, want) {
+		t.Errorf("reservations %v, want %v", inventoryQuery.calls, want)
+	}
+}
+
+func TestUpdateOrderTotal(t *testing.T) {
+	orderQuery := &fakeOrderQuery{lines: []*query.LinePrice{
+		{ItemID: "item-1", Price: money.New("IDR", 15000), Quantity: 3},
+		{ItemID: "item-2", Price: money.New("IDR", 2500), Quantity: 2},
+	}}
+	r := &orderRepository{orderQuery: orderQuery}
+
+	total, err := r.updateOrderTotal(context.Background(), nil, "order-1")
+	if err != nil {
+		t.Fatalf("updateOrderTotal() = %v", err)
+	}
+
+	if total.GetCurrencyCode() != "IDR" || total.GetMinorUnits() != 50000 {
+		t.Errorf("total = %v, want IDR 50000", total)
+	}
+	if orderQuery.total != total {
+		t.Errorf("stored total = %v, want %v", orderQuery.total, total)
+	}
+}
+
+func TestUpdateOrderTotalRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []*query.LinePrice
+		cause error
+	}{
+		{"mixed currencies", []*query.LinePrice{
+			{ItemID: "item-1", Price: money.New("IDR", 15000), Quantity: 1},
+			{ItemID: "item-2", Price: money.New("USD", 100), Quantity: 1},
+		}, money.ErrCurrencyMismatch},
+		{"line overflows", []*query.LinePrice{
+			{ItemID: "item-1", Price: money.New("IDR", math.MaxInt64/2), Quantity: 3},
+		}, money.ErrOverflow},
+		{"total overflows", []*query.LinePrice{
+			{ItemID: "item-1", Price: money.New("IDR", math.MaxInt64), Quantity: 1},
+			{ItemID: "item-2", Price: money.New("IDR", 1), Quantity: 1},
+		}, money.ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderQuery := &fakeOrderQuery{lines: tt.lines}
+			r := &orderRepository{orderQuery: orderQuery}
+
+			_, err := r.updateOrderTotal(context.Background(), nil, "order-1")
+
+			if !errors.Is(err, domainerr.InvalidArgument) || !errors.Is(err, tt.cause) {
+				t.Errorf("updateOrderTotal() = %v, want InvalidArgument wrapping %v", err, tt.cause)
+			}
+			if orderQuery.total != nil {
+				t.Errorf("stored total %v for a rejected order", orderQuery.total)
+			}
+		})
+	}
+}
+
+func TestUpdatePaymentStatusSettlesStock(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to payment.Status
+		token    string
+		want     []string
+		wantErr  error
+	}{
+		{"paid commits", payment.StatusPending, payment.StatusPaid, "tok", []string{"commit"}, nil},
+		{"failed releases", payment.StatusPending, payment.StatusFailed, "tok", []string{"release"}, nil},
+		{"expired releases", payment.StatusPending, payment.StatusExpired, "", []string{"release"}, nil},
+		{"refund keeps stock sold", payment.StatusPaid, payment.StatusRefunded, "tok", nil, nil},
+		{"repeated status", payment.StatusPaid, payment.StatusPaid, "tok", nil, nil},
+		{"final status", payment.StatusFailed, payment.StatusPaid, "tok", nil, payment.ErrInvalidTransition},
+		{"wrong token", payment.StatusPending, payment.StatusPaid, "other", nil, payment.ErrTokenMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderQuery := &fakeOrderQuery{status: tt.from, token: "tok"}
+			inventoryQuery := &fakeInventoryQuery{}
+			outboxQuery := &fakeOutboxQuery{}
+			r := &orderRepository{orderQuery: orderQuery, inventoryQuery: inventoryQuery, outboxQuery: outboxQuery}
+
+			err := r.updatePaymentStatus(context.Background(), nil, "order-1", tt.token, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("updatePaymentStatus() = %v, want %v", err, tt.wantErr)
+			}
+
+			if !slices.Equal(inventoryQuery.calls, tt.want) {
+				t.Errorf("reservations %v, want %v", inventoryQuery.calls, tt.want)
+			}
+			changed := tt.wantErr == nil && tt.from != tt.to
+			if got := len(outboxQuery.events) == 1; got != changed {
+				t.Errorf("appended %d events, want an event %v", len(outboxQuery.events), changed)
+			}
+		})
+	}
+}
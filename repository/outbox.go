@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/daffaromero/gorpc-template/repository/query"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	itemAggregate   = "item"
+	userAggregate   = "user"
+	orderAggregate  = "order"
+	sellerAggregate = "seller"
+)
+
+// appendEvent serializes event and writes it to the outbox inside tx, so the
+// event is only ever visible together with the write that produced it.
+func appendEvent(ctx context.Context, tx pgx.Tx, outboxQuery query.OutboxQuery, aggregateType, aggregateID string, event proto.Message) error {
+	payload, err := proto.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", aggregateType, err)
+	}
+
+	err = outboxQuery.AppendEvent(ctx, tx, &query.OutboxEvent{
+		ID:            uuid.NewString(),
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     string(event.ProtoReflect().Descriptor().FullName()),
+		Payload:       payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to append %s event: %w", aggregateType, err)
+	}
+
+	return nil
+}
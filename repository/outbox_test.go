@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository/query"
+)
+
+// fakeOutboxQuery records the events appended to the outbox.
+type fakeOutboxQuery struct {
+	query.OutboxQuery
+	events []*query.OutboxEvent
+}
+
+func (q *fakeOutboxQuery) AppendEvent(ctx context.Context, tx pgx.Tx, event *query.OutboxEvent) error {
+	q.events = append(q.events, event)
+	return nil
+}
+
+func TestAppendEvent(t *testing.T) {
+	outboxQuery := &fakeOutboxQuery{}
+
+	err := appendEvent(context.Background(), nil, outboxQuery, itemAggregate, "item-1", &api.ItemDeleted{Id: "item-1"})
+	if err != nil {
+		t.Fatalf("appendEvent() = %v", err)
+	}
+	if len(outboxQuery.events) != 1 {
+		t.Fatalf("appended %d events, want 1", len(outboxQuery.events))
+	}
+
+	event := outboxQuery.events[0]
+	if event.ID == "" || event.AggregateType != "item" || event.AggregateID != "item-1" {
+		t.Errorf("event = %+v", event)
+	}
+	if event.EventType != "ItemDeleted" {
+		t.Errorf("EventType = %q, want the full message name ItemDeleted", event.EventType)
+	}
+
+	var payload api.ItemDeleted
+	if err := proto.Unmarshal(event.Payload, &payload); err != nil || payload.Id != "item-1" {
+		t.Errorf("payload = %v, %v, want ItemDeleted for item-1", &payload, err)
+	}
+}
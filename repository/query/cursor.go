@@ -0,0 +1,62 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+)
+
+// Cursor marks the last row of a page for keyset pagination. Key holds the
+// sort column value and ID breaks ties between rows with equal keys.
+type Cursor struct {
+	Key string `json:"k"`
+	ID  string `json:"id"`
+}
+
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalidPageToken(err)
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, invalidPageToken(err)
+	}
+
+	if cursor.ID == "" {
+		return nil, invalidPageToken(nil)
+	}
+
+	return &cursor, nil
+}
+
+func timeCursor(t time.Time, id string) *Cursor {
+	return &Cursor{Key: t.UTC().Format(time.RFC3339Nano), ID: id}
+}
+
+func (c *Cursor) timeKey() (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, c.Key)
+	if err != nil {
+		return time.Time{}, invalidPageToken(err)
+	}
+	return t, nil
+}
+
+// invalidPageToken reports a page token that was not issued by this server.
+// The result wraps ErrInvalidPageToken and the decoding error, if any.
+func invalidPageToken(cause error) error {
+	err := domainerr.Invalid("page_token", "%s", ErrInvalidPageToken)
+	if cause == nil {
+		return err.WithCause(ErrInvalidPageToken)
+	}
+	return err.WithCause(fmt.Errorf("%w: %w", ErrInvalidPageToken, cause))
+}
@@ -0,0 +1,89 @@
+package query
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursors := []Cursor{
+		{Key: "widget", ID: "0191c5f2-7c1e-7d3a-9f00-000000000001"},
+		{Key: "", ID: "id"},
+		{Key: "name with spaces, commas and \"quotes\"", ID: "id/with+symbols"},
+	}
+
+	for _, cursor := range cursors {
+		token := cursor.Encode()
+
+		got, err := DecodeCursor(token)
+		if err != nil {
+			t.Fatalf("DecodeCursor(%q) error = %v", token, err)
+		}
+		if *got != cursor {
+			t.Errorf("DecodeCursor(Encode(%+v)) = %+v", cursor, *got)
+		}
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"not base64", "not a token!"},
+		{"not json", base64.RawURLEncoding.EncodeToString([]byte("widget"))},
+		{"missing id", base64.RawURLEncoding.EncodeToString([]byte(`{"k":"widget"}`))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := DecodeCursor(tt.token)
+			if err == nil {
+				t.Fatalf("DecodeCursor(%q) = %+v, want error", tt.token, cursor)
+			}
+			if !errors.Is(err, ErrInvalidPageToken) {
+				t.Errorf("error %v does not wrap ErrInvalidPageToken", err)
+			}
+			if !errors.Is(err, domainerr.InvalidArgument) {
+				t.Errorf("error %v is not InvalidArgument", err)
+			}
+
+			var domainErr *domainerr.Error
+			if errors.As(err, &domainErr) && (len(domainErr.Violations) != 1 || domainErr.Violations[0].Field != "page_token") {
+				t.Errorf("violations = %+v, want one for page_token", domainErr.Violations)
+			}
+		})
+	}
+}
+
+func TestTimeCursor(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 30, 45, 123456789, time.FixedZone("WIB", 7*60*60))
+
+	cursor, err := DecodeCursor(timeCursor(created, "id").Encode())
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+
+	key, err := cursor.timeKey()
+	if err != nil {
+		t.Fatalf("timeKey() error = %v", err)
+	}
+	if !key.Equal(created) {
+		t.Errorf("timeKey() = %v, want %v", key, created)
+	}
+	if key.Location() != time.UTC {
+		t.Errorf("timeKey() location = %v, want UTC", key.Location())
+	}
+}
+
+func TestTimeKeyInvalid(t *testing.T) {
+	cursor := &Cursor{Key: "yesterday", ID: "id"}
+
+	if _, err := cursor.timeKey(); !errors.Is(err, ErrInvalidPageToken) {
+		t.Errorf("timeKey() error = %v, want ErrInvalidPageToken", err)
+	}
+}
@@ -0,0 +1,77 @@
+package query
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+)
+
+var (
+	ErrInsufficientStock = errors.New("insufficient stock")
+
+	ErrInvalidPageToken = errors.New("invalid page token")
+)
+
+// PostgreSQL error codes, see
+// https://www.postgresql.org/docs/current/errcodes-appendix.html.
+const (
+	codeUniqueViolation      = "23505"
+	codeForeignKeyViolation  = "23503"
+	codeNotNullViolation     = "23502"
+	codeCheckViolation       = "23514"
+	codeInvalidText          = "22P02"
+	codeStringTooLong        = "22001"
+	codeNumericOutOfRange    = "22003"
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+)
+
+// pgCode returns the PostgreSQL error code of err, or "" if err did not come
+// from the server.
+func pgCode(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	return ""
+}
+
+// classify converts pgx.ErrNoRows and server errors into domain errors about
+// the resource a statement works on. Errors it cannot classify, such as
+// connection failures, are returned unchanged. Statements that know more
+// about a violation, e.g. which referenced row is missing, check pgCode
+// themselves before falling back to classify.
+func classify(err error, resourceType, name string) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domainerr.ResourceNotFound(resourceType, name)
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case codeUniqueViolation:
+		return domainerr.ResourceExists(resourceType, name).WithCause(err)
+	case codeForeignKeyViolation:
+		return domainerr.ForResource(domainerr.FailedPrecondition, resourceType, name, "references or is referenced by another resource").WithCause(err)
+	case codeNotNullViolation, codeCheckViolation, codeInvalidText, codeStringTooLong, codeNumericOutOfRange:
+		field := pgErr.ColumnName
+		if field == "" {
+			field = resourceType
+		}
+		return domainerr.Invalid(field, "%s", pgErr.Message).WithCause(err)
+	case codeSerializationFailure, codeDeadlockDetected:
+		return domainerr.ForResource(domainerr.Conflict, resourceType, name, "was modified concurrently").WithCause(err)
+	default:
+		return err
+	}
+}
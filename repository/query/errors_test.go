@@ -0,0 +1,78 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		kind domainerr.Kind
+	}{
+		{"no rows", pgx.ErrNoRows, domainerr.NotFound},
+		{"wrapped no rows", fmt.Errorf("scan: %w", pgx.ErrNoRows), domainerr.NotFound},
+		{"unique violation", &pgconn.PgError{Code: codeUniqueViolation}, domainerr.AlreadyExists},
+		{"foreign key violation", &pgconn.PgError{Code: codeForeignKeyViolation}, domainerr.FailedPrecondition},
+		{"not null violation", &pgconn.PgError{Code: codeNotNullViolation, ColumnName: "name"}, domainerr.InvalidArgument},
+		{"check violation", &pgconn.PgError{Code: codeCheckViolation}, domainerr.InvalidArgument},
+		{"invalid uuid", &pgconn.PgError{Code: codeInvalidText}, domainerr.InvalidArgument},
+		{"too long", &pgconn.PgError{Code: codeStringTooLong}, domainerr.InvalidArgument},
+		{"out of range", &pgconn.PgError{Code: codeNumericOutOfRange}, domainerr.InvalidArgument},
+		{"serialization failure", &pgconn.PgError{Code: codeSerializationFailure}, domainerr.Conflict},
+		{"deadlock", &pgconn.PgError{Code: codeDeadlockDetected}, domainerr.Conflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classify(tt.err, "item", "42")
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("classify() = %v, want %v", err, tt.kind)
+			}
+
+			var domainErr *domainerr.Error
+			if !errors.As(err, &domainErr) {
+				t.Fatalf("classify() = %T, want a domain error", err)
+			}
+			// Server errors stay available for logs and retries.
+			var pgErr *pgconn.PgError
+			if errors.As(tt.err, &pgErr) && !errors.As(err, &pgErr) {
+				t.Errorf("classify() = %v dropped the server error", err)
+			}
+		})
+	}
+}
+
+func TestClassifyInvalidField(t *testing.T) {
+	err := classify(&pgconn.PgError{Code: codeNotNullViolation, ColumnName: "name", Message: "null value"}, "item", "42")
+
+	var domainErr *domainerr.Error
+	if !errors.As(err, &domainErr) || len(domainErr.Violations) != 1 || domainErr.Violations[0].Field != "name" {
+		t.Errorf("classify() = %+v, want a violation of name", domainErr)
+	}
+
+	err = classify(&pgconn.PgError{Code: codeCheckViolation, Message: "violates check"}, "item", "42")
+	if !errors.As(err, &domainErr) || domainErr.Violations[0].Field != "item" {
+		t.Errorf("classify() = %+v, want a violation of item when the column is unknown", domainErr)
+	}
+}
+
+func TestClassifyUnknown(t *testing.T) {
+	for _, err := range []error{
+		nil,
+		io.ErrUnexpectedEOF,
+		&pgconn.PgError{Code: "57P01"},
+	} {
+		if got := classify(err, "item", "42"); got != err {
+			t.Errorf("classify(%v) = %v, want it unchanged", err, got)
+		}
+	}
+}
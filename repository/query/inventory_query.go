@@ -0,0 +1,140 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+)
+
+type InventoryQuery interface {
+	ReserveStock(ctx context.Context, tx pgx.Tx, orderID string) ([]*StockChange, error)
+	ReleaseReservations(ctx context.Context, tx pgx.Tx, orderID string) error
+	CommitReservations(ctx context.Context, tx pgx.Tx, orderID string) error
+	AdjustStock(ctx context.Context, tx pgx.Tx, itemID string, delta int32) (*StockChange, error)
+}
+
+// StockChange records the available stock of an item before and after a
+// write.
+type StockChange struct {
+	ItemID string
+	Before int32
+	After  int32
+}
+
+type inventoryQuery struct {
+	db *pgxpool.Pool
+}
+
+func NewInventoryQuery(db *pgxpool.Pool) *inventoryQuery {
+	return &inventoryQuery{db: db}
+}
+
+// ReserveStock takes the quantities of an order's line items out of the
+// available stock and records them as reservations. Item rows are locked in
+// ID order, so concurrent orders for the same items queue up instead of
+// deadlocking. It fails with a FailedPrecondition error wrapping
+// ErrInsufficientStock, reserving nothing, when any item has too little
+// stock.
+func (q *inventoryQuery) ReserveStock(ctx context.Context, tx pgx.Tx, orderID string) ([]*StockChange, error) {
+	query := `SELECT i.id, i.stock, oi.quantity
+		FROM order_items oi
+		JOIN items i ON i.id = oi.item_id
+		WHERE oi.order_id = $1
+		ORDER BY i.id
+		FOR UPDATE OF i`
+
+	rows, err := tx.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, classify(err, "order", orderID)
+	}
+
+	var changes []*StockChange
+	for rows.Next() {
+		var change StockChange
+		var quantity int32
+		if err := rows.Scan(&change.ItemID, &change.Before, &quantity); err != nil {
+			rows.Close()
+			return nil, classify(err, "order", orderID)
+		}
+		change.After = change.Before - quantity
+		changes = append(changes, &change)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, classify(err, "order", orderID)
+	}
+
+	for _, change := range changes {
+		if change.After < 0 {
+			return nil, insufficientStock(change.ItemID, fmt.Sprintf("has only %d left", change.Before))
+		}
+	}
+
+	updateQuery := `UPDATE items SET stock = items.stock - oi.quantity
+		FROM order_items oi
+		WHERE oi.order_id = $1 AND oi.item_id = items.id`
+	if _, err := tx.Exec(ctx, updateQuery, orderID); err != nil {
+		return nil, classify(err, "order", orderID)
+	}
+
+	reserveQuery := `INSERT INTO stock_reservations (order_id, item_id, quantity, status)
+		SELECT order_id, item_id, quantity, 'reserved' FROM order_items WHERE order_id = $1
+		ON CONFLICT (order_id, item_id) DO UPDATE
+		SET quantity = EXCLUDED.quantity, status = 'reserved', updated_at = NOW()`
+	if _, err := tx.Exec(ctx, reserveQuery, orderID); err != nil {
+		return nil, classify(err, "order", orderID)
+	}
+
+	return changes, nil
+}
+
+// ReleaseReservations returns the reserved stock of an order to the items.
+// Committed reservations are left alone.
+func (q *inventoryQuery) ReleaseReservations(ctx context.Context, tx pgx.Tx, orderID string) error {
+	query := `WITH released AS (
+			UPDATE stock_reservations SET status = 'released', updated_at = NOW()
+			WHERE order_id = $1 AND status = 'reserved'
+			RETURNING item_id, quantity
+		)
+		UPDATE items SET stock = items.stock + released.quantity
+		FROM released
+		WHERE items.id = released.item_id`
+
+	_, err := tx.Exec(ctx, query, orderID)
+	return classify(err, "order", orderID)
+}
+
+// CommitReservations marks the reserved stock of an order as sold.
+func (q *inventoryQuery) CommitReservations(ctx context.Context, tx pgx.Tx, orderID string) error {
+	query := `UPDATE stock_reservations SET status = 'committed', updated_at = NOW()
+		WHERE order_id = $1 AND status = 'reserved'`
+
+	_, err := tx.Exec(ctx, query, orderID)
+	return classify(err, "order", orderID)
+}
+
+// AdjustStock adds delta, which may be negative, to the available stock of
+// an item. Stock cannot drop below zero.
+func (q *inventoryQuery) AdjustStock(ctx context.Context, tx pgx.Tx, itemID string, delta int32) (*StockChange, error) {
+	query := `UPDATE items SET stock = stock + $1 WHERE id = $2 RETURNING stock`
+
+	change := StockChange{ItemID: itemID}
+	err := tx.QueryRow(ctx, query, delta, itemID).Scan(&change.After)
+	if err != nil {
+		if pgCode(err) == codeCheckViolation {
+			return nil, insufficientStock(itemID, "cannot go below zero")
+		}
+		return nil, classify(err, "item", itemID)
+	}
+	change.Before = change.After - delta
+
+	return &change, nil
+}
+
+func insufficientStock(itemID, outcome string) error {
+	return domainerr.ForResource(domainerr.FailedPrecondition, "item", itemID, outcome).WithCause(ErrInsufficientStock)
+}
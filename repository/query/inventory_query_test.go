@@ -0,0 +1,29 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+)
+
+func TestAdjustStockBelowZero(t *testing.T) {
+	tx := &fakeTx{rows: []fakeRow{{err: &pgconn.PgError{Code: codeCheckViolation, ConstraintName: "items_stock_check"}}}}
+
+	_, err := NewInventoryQuery(nil).AdjustStock(context.Background(), tx, "item-1", -5)
+
+	if !errors.Is(err, ErrInsufficientStock) || !errors.Is(err, domainerr.FailedPrecondition) {
+		t.Errorf("AdjustStock() = %v, want FailedPrecondition wrapping ErrInsufficientStock", err)
+	}
+}
+
+func TestAdjustStockMissingItem(t *testing.T) {
+	_, err := NewInventoryQuery(nil).AdjustStock(context.Background(), &fakeTx{}, "item-1", 5)
+
+	if !errors.Is(err, domainerr.NotFound) {
+		t.Errorf("AdjustStock() = %v, want NotFound", err)
+	}
+}
@@ -4,22 +4,32 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/helper/money"
 	"github.com/daffaromero/gorpc-template/protobuf/api"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type ItemQuery interface {
 	CreateItem(ctx context.Context, tx pgx.Tx, item *api.Item) (*api.Item, error)
 	GetItem(ctx context.Context, id string) (*api.Item, error)
-	ListItems(ctx context.Context) ([]*api.Item, error)
+	ListItems(ctx context.Context, page Page) ([]*api.Item, *Cursor, error)
+	CountItems(ctx context.Context) (int32, error)
+	ListSellerItems(ctx context.Context, sellerID string, page Page) ([]*api.Item, *Cursor, error)
+	CountSellerItems(ctx context.Context, sellerID string) (int32, error)
 	UpdateItem(ctx context.Context, tx pgx.Tx, item *api.Item) (*api.Item, error)
 	DeleteItem(ctx context.Context, tx pgx.Tx, id string) error
 }
 
+// itemColumns is the column list read by scanItem.
+const itemColumns = `id, name, description, COALESCE(seller_id::text, ''), price, currency, stock, created_at, updated_at, version`
+
 type itemQuery struct {
 	db *pgxpool.Pool
 }
@@ -30,23 +40,23 @@ func NewItemQuery(db *pgxpool.Pool) *itemQuery {
 
 func (q *itemQuery) CreateItem(ctx context.Context, tx pgx.Tx, item *api.Item) (*api.Item, error) {
 	if item == nil {
-		return nil, errors.New("item cannot be nil")
+		return nil, domainerr.Invalid("item", "must be set")
 	}
 
-	query := `INSERT INTO items (id, name, description) VALUES ($1, $2, $3) RETURNING id, name, description`
+	query := `INSERT INTO items (id, name, description, seller_id, price, currency, stock)
+		VALUES ($1, $2, $3, NULLIF($4, '')::uuid, $5, $6, $7)
+		RETURNING ` + itemColumns
+
+	price := item.GetPrice()
+	row := tx.QueryRow(ctx, query, item.Id, item.Name, item.Description, item.SellerId, price.GetMinorUnits(), price.GetCurrencyCode(), item.Stock)
 
 	var createdItem api.Item
-	err := tx.QueryRow(ctx, query, item.Id, item.Name, item.Description).Scan(&createdItem.Id, &createdItem.Name, &createdItem.Description)
+	err := scanItem(row, &createdItem)
 	if err != nil {
-		if pgErr, ok := err.(*pgconn.PgError); ok {
-			switch pgErr.Code {
-			case "23505": // unique_violation
-				return nil, fmt.Errorf("item with ID %s already exists: %w", item.Id, err)
-			case "23502": // not_null_violation
-				return nil, fmt.Errorf("missing required field: %w", err)
-			}
+		if pgCode(err) == codeForeignKeyViolation {
+			return nil, domainerr.ResourceNotFound("seller", item.SellerId).WithCause(err)
 		}
-		return nil, fmt.Errorf("failed to create item: %w", err)
+		return nil, classify(err, "item", item.Id)
 	}
 
 	return &createdItem, nil
@@ -54,31 +64,60 @@ func (q *itemQuery) CreateItem(ctx context.Context, tx pgx.Tx, item *api.Item) (
 
 func (q *itemQuery) GetItem(ctx context.Context, id string) (*api.Item, error) {
 	if id == "" {
-		return nil, errors.New("id cannot be empty")
+		return nil, domainerr.Invalid("id", "must not be empty")
 	}
 
-	query := `SELECT id, name, description FROM items WHERE id = $1`
+	query := `SELECT ` + itemColumns + ` FROM items WHERE id = $1`
 
-	row := q.db.QueryRow(ctx, query, id)
+	row := conn(ctx, q.db).QueryRow(ctx, query, id)
 
 	var item api.Item
-	err := row.Scan(&item.Id, &item.Name, &item.Description)
+	err := scanItem(row, &item)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("item with ID %s not found", id)
-		}
-		return nil, err
+		return nil, classify(err, "item", id)
 	}
 
 	return &item, nil
 }
 
-func (q *itemQuery) ListItems(ctx context.Context) ([]*api.Item, error) {
-	query := `SELECT id, name, description FROM items`
+// ListItems returns one page of items ordered by name. The returned cursor
+// points at the last item of the page and is nil when there are no more items.
+func (q *itemQuery) ListItems(ctx context.Context, page Page) ([]*api.Item, *Cursor, error) {
+	return q.listItems(ctx, page, nil)
+}
+
+// ListSellerItems is ListItems restricted to the items of one seller.
+func (q *itemQuery) ListSellerItems(ctx context.Context, sellerID string, page Page) ([]*api.Item, *Cursor, error) {
+	return q.listItems(ctx, page, &sellerID)
+}
+
+func (q *itemQuery) listItems(ctx context.Context, page Page, sellerID *string) ([]*api.Item, *Cursor, error) {
+	var conditions []string
+	args := []any{page.Limit() + 1}
+
+	if sellerID != nil {
+		args = append(args, *sellerID)
+		conditions = append(conditions, fmt.Sprintf("seller_id = $%d", len(args)))
+	}
+
+	pagination := "LIMIT $1"
+	if page.After != nil {
+		args = append(args, page.After.Key, page.After.ID)
+		conditions = append(conditions, fmt.Sprintf("(name, id) > ($%d, $%d)", len(args)-1, len(args)))
+	} else {
+		args = append(args, page.Offset())
+		pagination = fmt.Sprintf("LIMIT $1 OFFSET $%d", len(args))
+	}
+
+	query := `SELECT ` + itemColumns + ` FROM items`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY name, id ` + pagination
 
-	rows, err := q.db.Query(ctx, query)
+	rows, err := conn(ctx, q.db).Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, nil, classify(err, "item", "")
 	}
 
 	defer rows.Close()
@@ -86,22 +125,67 @@ func (q *itemQuery) ListItems(ctx context.Context) ([]*api.Item, error) {
 
 	for rows.Next() {
 		var item api.Item
-		err := rows.Scan(&item.Id, &item.Name, &item.Description)
+		err := scanItem(rows, &item)
 		if err != nil {
-			return nil, err
+			return nil, nil, classify(err, "item", "")
 		}
 		items = append(items, &item)
 	}
-	return items, nil
+	if err := rows.Err(); err != nil {
+		return nil, nil, classify(err, "item", "")
+	}
+
+	// One extra row is fetched to find out whether another page exists.
+	if int32(len(items)) <= page.Limit() {
+		return items, nil, nil
+	}
+
+	items = items[:page.Limit()]
+	last := items[len(items)-1]
+
+	return items, &Cursor{Key: last.Name, ID: last.Id}, nil
+}
+
+func (q *itemQuery) CountItems(ctx context.Context) (int32, error) {
+	query := `SELECT COUNT(*) FROM items`
+
+	var count int32
+	if err := conn(ctx, q.db).QueryRow(ctx, query).Scan(&count); err != nil {
+		return 0, classify(err, "item", "")
+	}
+
+	return count, nil
+}
+
+func (q *itemQuery) CountSellerItems(ctx context.Context, sellerID string) (int32, error) {
+	query := `SELECT COUNT(*) FROM items WHERE seller_id = $1`
+
+	var count int32
+	if err := conn(ctx, q.db).QueryRow(ctx, query, sellerID).Scan(&count); err != nil {
+		return 0, classify(err, "seller", sellerID)
+	}
+
+	return count, nil
 }
 
 func (q *itemQuery) UpdateItem(ctx context.Context, tx pgx.Tx, item *api.Item) (*api.Item, error) {
-	query := `UPDATE items SET name = $1, description = $2 WHERE id = $3 RETURNING id, name, description`
+	query := `UPDATE items SET name = $1, description = $2, seller_id = NULLIF($3, '')::uuid, price = $4, currency = $5
+		WHERE id = $6 AND version = $7
+		RETURNING ` + itemColumns
+
+	price := item.GetPrice()
+	row := tx.QueryRow(ctx, query, item.Name, item.Description, item.SellerId, price.GetMinorUnits(), price.GetCurrencyCode(), item.Id, item.Version)
 
 	var updatedItem api.Item
-	err := tx.QueryRow(ctx, query, item.Name, item.Description, item.Id).Scan(&updatedItem.Id, &updatedItem.Name, &updatedItem.Description)
+	err := scanItem(row, &updatedItem)
 	if err != nil {
-		return nil, err
+		if pgCode(err) == codeForeignKeyViolation {
+			return nil, domainerr.ResourceNotFound("seller", item.SellerId).WithCause(err)
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, versionConflict(ctx, tx, "items", "", "item", item.Id)
+		}
+		return nil, classify(err, "item", item.Id)
 	}
 
 	return &updatedItem, nil
@@ -110,10 +194,38 @@ func (q *itemQuery) UpdateItem(ctx context.Context, tx pgx.Tx, item *api.Item) (
 func (q *itemQuery) DeleteItem(ctx context.Context, tx pgx.Tx, id string) error {
 	query := `DELETE FROM items WHERE id = $1`
 
-	_, err := tx.Exec(ctx, query, id)
+	tag, err := tx.Exec(ctx, query, id)
 	if err != nil {
+		if pgCode(err) == codeForeignKeyViolation {
+			return domainerr.ForResource(domainerr.FailedPrecondition, "item", id, "is still part of orders").WithCause(err)
+		}
+		return classify(err, "item", id)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domainerr.ResourceNotFound("item", id)
+	}
+
+	return nil
+}
+
+// scanItem scans a row selected with itemColumns into item. A NULL
+// description becomes an empty string.
+func scanItem(row rowScanner, item *api.Item) error {
+	var description *string
+	var price int64
+	var currency string
+	var createdAt, updatedAt time.Time
+
+	if err := row.Scan(&item.Id, &item.Name, &description, &item.SellerId, &price, &currency, &item.Stock, &createdAt, &updatedAt, &item.Version); err != nil {
 		return err
 	}
 
+	if description != nil {
+		item.Description = *description
+	}
+	item.Price = money.New(currency, price)
+	item.CreatedAt = timestamppb.New(createdAt)
+	item.UpdatedAt = timestamppb.New(updatedAt)
 	return nil
 }
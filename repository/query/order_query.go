@@ -2,21 +2,47 @@ package query
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/helper/money"
+	"github.com/daffaromero/gorpc-template/payment"
 	"github.com/daffaromero/gorpc-template/protobuf/api"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type OrderQuery interface {
 	CreateOrder(ctx context.Context, tx pgx.Tx, order *api.Order) (*api.Order, error)
 	GetOrder(ctx context.Context, id string) (*api.Order, error)
-	ListOrders(ctx context.Context) ([]*api.Order, error)
+	ListOrders(ctx context.Context, page Page, showDeleted bool) ([]*api.Order, *Cursor, error)
+	CountOrders(ctx context.Context, showDeleted bool) (int32, error)
 	UpdateOrder(ctx context.Context, tx pgx.Tx, order *api.Order) (*api.Order, error)
 	DeleteOrder(ctx context.Context, tx pgx.Tx, id string) error
+	RestoreOrder(ctx context.Context, tx pgx.Tx, id string) (*api.Order, error)
+	PurgeOrders(ctx context.Context, tx pgx.Tx, deletedBefore time.Time) (int64, error)
+	CreateOrderItems(ctx context.Context, tx pgx.Tx, orderID string, items []*api.OrderItem) error
+	DeleteOrderItems(ctx context.Context, tx pgx.Tx, orderID string) error
+	ListLinePrices(ctx context.Context, tx pgx.Tx, orderID string) ([]*LinePrice, error)
+	SetOrderTotal(ctx context.Context, tx pgx.Tx, orderID string, total *api.Money) error
+	SetPaymentDetails(ctx context.Context, tx pgx.Tx, orderID string, details *payment.Payment) error
+	GetPaymentForUpdate(ctx context.Context, tx pgx.Tx, orderID string) (payment.Status, string, error)
+	UpdatePaymentStatus(ctx context.Context, tx pgx.Tx, orderID string, status payment.Status) error
 }
 
+// LinePrice is the current unit price and quantity of an order line.
+type LinePrice struct {
+	ItemID   string
+	Price    *api.Money
+	Quantity int32
+}
+
+// orderColumns is the column list read by scanOrder.
+const orderColumns = `id, user_id, total_price, COALESCE(currency, ''), payment_status, COALESCE(payment_url, ''), deleted_at, created_at, updated_at, version`
+
 type orderQuery struct {
 	db *pgxpool.Pool
 }
@@ -26,70 +52,419 @@ func NewOrderQuery(db *pgxpool.Pool) *orderQuery {
 }
 
 func (q *orderQuery) CreateOrder(ctx context.Context, tx pgx.Tx, order *api.Order) (*api.Order, error) {
-	query := `INSERT INTO orders (id, user_id, items) VALUES ($1, $2, $3) RETURNING id, user_id, items`
+	if order == nil {
+		return nil, domainerr.Invalid("order", "must be set")
+	}
+
+	// The select keeps orders from being placed for soft-deleted users, which
+	// the foreign key alone would allow.
+	query := `INSERT INTO orders (id, user_id, total_price, payment_status)
+		SELECT $1, $2, 0, 'pending'
+		WHERE EXISTS (SELECT 1 FROM users WHERE id = $2 AND deleted_at IS NULL)
+		RETURNING ` + orderColumns
 
 	var createdOrder api.Order
-	err := tx.QueryRow(ctx, query, order.Id, order.UserId, order.Items).Scan(&createdOrder.Id, &createdOrder.UserId, &createdOrder.Items)
+	err := scanOrder(tx.QueryRow(ctx, query, order.Id, order.UserId), &createdOrder)
 	if err != nil {
-		return nil, err
+		if errors.Is(err, pgx.ErrNoRows) || pgCode(err) == codeForeignKeyViolation {
+			return nil, domainerr.ResourceNotFound("user", order.UserId).WithCause(err)
+		}
+		return nil, classify(err, "order", order.Id)
 	}
 
 	return &createdOrder, nil
-
 }
 
 func (q *orderQuery) GetOrder(ctx context.Context, id string) (*api.Order, error) {
-	query := `SELECT id, user_id, items FROM orders WHERE id = $1`
+	if id == "" {
+		return nil, domainerr.Invalid("id", "must not be empty")
+	}
+
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE id = $1 AND deleted_at IS NULL`
 
 	var order api.Order
-	err := q.db.QueryRow(ctx, query, id).Scan(&order.Id, &order.UserId, &order.Items)
+	err := scanOrder(conn(ctx, q.db).QueryRow(ctx, query, id), &order)
 	if err != nil {
+		return nil, classify(err, "order", id)
+	}
+
+	if err := q.attachOrderItems(ctx, []*api.Order{&order}); err != nil {
 		return nil, err
 	}
 
 	return &order, nil
 }
 
-func (q *orderQuery) ListOrders(ctx context.Context) ([]*api.Order, error) {
-	query := `SELECT id, user_id, items FROM orders`
+// ListOrders returns one page of orders, newest first. Soft-deleted orders are
+// only included when showDeleted is set. The returned cursor points at the
+// last order of the page and is nil when there are no more orders.
+func (q *orderQuery) ListOrders(ctx context.Context, page Page, showDeleted bool) ([]*api.Order, *Cursor, error) {
+	query := `SELECT ` + orderColumns + ` FROM orders
+		WHERE ($3 OR deleted_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1 OFFSET $2`
+	args := []any{page.Limit() + 1, page.Offset(), showDeleted}
+
+	if page.After != nil {
+		after, err := page.After.timeKey()
+		if err != nil {
+			return nil, nil, err
+		}
 
-	rows, err := q.db.Query(ctx, query)
+		query = `SELECT ` + orderColumns + ` FROM orders
+			WHERE (created_at, id) < ($2, $3) AND ($4 OR deleted_at IS NULL)
+			ORDER BY created_at DESC, id DESC
+			LIMIT $1`
+		args = []any{page.Limit() + 1, after, page.After.ID, showDeleted}
+	}
+
+	rows, err := conn(ctx, q.db).Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, nil, classify(err, "order", "")
 	}
+	defer rows.Close()
 
 	var orders []*api.Order
 	for rows.Next() {
 		var order api.Order
-		err := rows.Scan(&order.Id, &order.UserId, &order.Items)
+		err := scanOrder(rows, &order)
 		if err != nil {
-			return nil, err
+			return nil, nil, classify(err, "order", "")
 		}
 		orders = append(orders, &order)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, classify(err, "order", "")
+	}
 
-	return orders, nil
+	// One extra row is fetched to find out whether another page exists.
+	var next *Cursor
+	if int32(len(orders)) > page.Limit() {
+		orders = orders[:page.Limit()]
+		last := orders[len(orders)-1]
+		next = timeCursor(last.CreatedAt.AsTime(), last.Id)
+	}
+
+	if err := q.attachOrderItems(ctx, orders); err != nil {
+		return nil, nil, err
+	}
+
+	return orders, next, nil
+}
+
+func (q *orderQuery) CountOrders(ctx context.Context, showDeleted bool) (int32, error) {
+	query := `SELECT COUNT(*) FROM orders WHERE ($1 OR deleted_at IS NULL)`
+
+	var count int32
+	if err := conn(ctx, q.db).QueryRow(ctx, query, showDeleted).Scan(&count); err != nil {
+		return 0, classify(err, "order", "")
+	}
+
+	return count, nil
 }
 
 func (q *orderQuery) UpdateOrder(ctx context.Context, tx pgx.Tx, order *api.Order) (*api.Order, error) {
-	query := `UPDATE orders SET user_id = $1, items = $2 WHERE id = $3 RETURNING id, user_id, items`
+	// The line items change along with the order, so the version is always
+	// incremented here; no trigger sees them. As in CreateOrder, the EXISTS
+	// keeps orders from being moved to soft-deleted users.
+	query := `UPDATE orders SET user_id = $1, version = version + 1
+		WHERE id = $2 AND version = $3 AND deleted_at IS NULL
+		AND EXISTS (SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)
+		RETURNING ` + orderColumns
 
 	var updatedOrder api.Order
-	err := tx.QueryRow(ctx, query, order.UserId, order.Items, order.Id).Scan(&updatedOrder.Id, &updatedOrder.UserId, &updatedOrder.Items)
+	err := scanOrder(tx.QueryRow(ctx, query, order.UserId, order.Id, order.Version), &updatedOrder)
 	if err != nil {
-		return nil, err
+		if pgCode(err) == codeForeignKeyViolation {
+			return nil, domainerr.ResourceNotFound("user", order.UserId).WithCause(err)
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			if err := missingUser(ctx, tx, order.UserId); err != nil {
+				return nil, err
+			}
+			return nil, versionConflict(ctx, tx, "orders", "deleted_at IS NULL", "order", order.Id)
+		}
+		return nil, classify(err, "order", order.Id)
 	}
 
 	return &updatedOrder, nil
 }
 
+// missingUser returns NotFound when userID is missing or soft-deleted, which
+// is one reason for UpdateOrder to match no row.
+func missingUser(ctx context.Context, tx pgx.Tx, userID string) error {
+	query := `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)`
+
+	var userExists bool
+	if err := tx.QueryRow(ctx, query, userID).Scan(&userExists); err != nil {
+		return classify(err, "user", userID)
+	}
+
+	if !userExists {
+		return domainerr.ResourceNotFound("user", userID)
+	}
+	return nil
+}
+
+// DeleteOrder soft-deletes an order. Its line items are kept so that
+// RestoreOrder can bring the order back unchanged.
 func (q *orderQuery) DeleteOrder(ctx context.Context, tx pgx.Tx, id string) error {
-	query := `DELETE FROM orders WHERE id = $1`
+	query := `UPDATE orders SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+
+	tag, err := tx.Exec(ctx, query, id)
+	if err != nil {
+		return classify(err, "order", id)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domainerr.ResourceNotFound("order", id)
+	}
+
+	return nil
+}
+
+func (q *orderQuery) RestoreOrder(ctx context.Context, tx pgx.Tx, id string) (*api.Order, error) {
+	query := `UPDATE orders SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL RETURNING ` + orderColumns
+
+	var restoredOrder api.Order
+	err := scanOrder(tx.QueryRow(ctx, query, id), &restoredOrder)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domainerr.ForResource(domainerr.NotFound, "order", id, "not found among deleted orders")
+		}
+		return nil, classify(err, "order", id)
+	}
+
+	return &restoredOrder, nil
+}
+
+// PurgeOrders hard-deletes orders that were soft-deleted before
+// deletedBefore, together with their line items and stock reservations. It
+// returns the number of orders removed.
+func (q *orderQuery) PurgeOrders(ctx context.Context, tx pgx.Tx, deletedBefore time.Time) (int64, error) {
+	for _, childQuery := range []string{
+		`DELETE FROM stock_reservations WHERE order_id IN (SELECT id FROM orders WHERE deleted_at < $1)`,
+		`DELETE FROM order_items WHERE order_id IN (SELECT id FROM orders WHERE deleted_at < $1)`,
+	} {
+		if _, err := tx.Exec(ctx, childQuery, deletedBefore); err != nil {
+			return 0, classify(err, "order", "")
+		}
+	}
+
+	query := `DELETE FROM orders WHERE deleted_at < $1`
+
+	tag, err := tx.Exec(ctx, query, deletedBefore)
+	if err != nil {
+		return 0, classify(err, "order", "")
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (q *orderQuery) CreateOrderItems(ctx context.Context, tx pgx.Tx, orderID string, items []*api.OrderItem) error {
+	query := `INSERT INTO order_items (order_id, item_id, quantity) VALUES ($1, $2, $3)`
+
+	batch := &pgx.Batch{}
+	for _, orderItem := range items {
+		batch.Queue(query, orderID, orderItem.GetItem().GetId(), orderItem.GetQuantity())
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for _, orderItem := range items {
+		if _, err := results.Exec(); err != nil {
+			itemID := orderItem.GetItem().GetId()
+			switch pgCode(err) {
+			case codeForeignKeyViolation:
+				return domainerr.ResourceNotFound("item", itemID).WithCause(err)
+			case codeUniqueViolation:
+				return domainerr.ForResource(domainerr.AlreadyExists, "item", itemID, "is already in the order").WithCause(err)
+			}
+			return classify(err, "item", itemID)
+		}
+	}
+
+	return nil
+}
+
+func (q *orderQuery) DeleteOrderItems(ctx context.Context, tx pgx.Tx, orderID string) error {
+	query := `DELETE FROM order_items WHERE order_id = $1`
+
+	_, err := tx.Exec(ctx, query, orderID)
+	if err != nil {
+		return classify(err, "order", orderID)
+	}
+
+	return nil
+}
+
+// ListLinePrices returns the line items of an order with the current price
+// of each item. The item rows stay share-locked until tx ends so that prices
+// cannot change while the total is computed.
+func (q *orderQuery) ListLinePrices(ctx context.Context, tx pgx.Tx, orderID string) ([]*LinePrice, error) {
+	query := `SELECT i.id, i.price, i.currency, oi.quantity
+		FROM order_items oi
+		JOIN items i ON i.id = oi.item_id
+		WHERE oi.order_id = $1
+		ORDER BY i.id
+		FOR SHARE OF i`
+
+	rows, err := tx.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, classify(err, "order", orderID)
+	}
+	defer rows.Close()
+
+	var lines []*LinePrice
+	for rows.Next() {
+		var line LinePrice
+		var price int64
+		var currency string
+		if err := rows.Scan(&line.ItemID, &price, &currency, &line.Quantity); err != nil {
+			return nil, classify(err, "order", orderID)
+		}
+		line.Price = money.New(currency, price)
+		lines = append(lines, &line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, classify(err, "order", orderID)
+	}
+
+	return lines, nil
+}
+
+func (q *orderQuery) SetOrderTotal(ctx context.Context, tx pgx.Tx, orderID string, total *api.Money) error {
+	query := `UPDATE orders SET total_price = $1, currency = $2 WHERE id = $3`
+
+	tag, err := tx.Exec(ctx, query, total.GetMinorUnits(), total.GetCurrencyCode(), orderID)
+	if err != nil {
+		return classify(err, "order", orderID)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domainerr.ResourceNotFound("order", orderID)
+	}
+
+	return nil
+}
+
+func (q *orderQuery) SetPaymentDetails(ctx context.Context, tx pgx.Tx, orderID string, details *payment.Payment) error {
+	query := `UPDATE orders SET payment_url = $1, payment_token = $2 WHERE id = $3`
+
+	tag, err := tx.Exec(ctx, query, details.URL, details.Token, orderID)
+	if err != nil {
+		return classify(err, "order", orderID)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domainerr.ResourceNotFound("order", orderID)
+	}
+
+	return nil
+}
+
+// GetPaymentForUpdate returns the payment status and token of an order and
+// locks the row until tx ends, so concurrent callbacks are applied one by one.
+func (q *orderQuery) GetPaymentForUpdate(ctx context.Context, tx pgx.Tx, orderID string) (payment.Status, string, error) {
+	query := `SELECT payment_status, COALESCE(payment_token, '') FROM orders WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+
+	var status, token string
+	err := tx.QueryRow(ctx, query, orderID).Scan(&status, &token)
+	if err != nil {
+		return "", "", classify(err, "order", orderID)
+	}
+
+	return payment.Status(status), token, nil
+}
+
+func (q *orderQuery) UpdatePaymentStatus(ctx context.Context, tx pgx.Tx, orderID string, status payment.Status) error {
+	query := `UPDATE orders SET payment_status = $1 WHERE id = $2`
+
+	tag, err := tx.Exec(ctx, query, string(status), orderID)
+	if err != nil {
+		return classify(err, "order", orderID)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domainerr.ResourceNotFound("order", orderID)
+	}
+
+	return nil
+}
+
+// attachOrderItems loads the line items of all given orders with a single
+// join and assigns them to their orders.
+func (q *orderQuery) attachOrderItems(ctx context.Context, orders []*api.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(orders))
+	byID := make(map[string]*api.Order, len(orders))
+	for _, order := range orders {
+		ids = append(ids, order.Id)
+		byID[order.Id] = order
+	}
+
+	query := `SELECT oi.order_id, oi.quantity, i.id, i.name, i.description, COALESCE(i.seller_id::text, ''), i.price, i.currency, i.stock, i.created_at, i.updated_at
+		FROM order_items oi
+		JOIN items i ON i.id = oi.item_id
+		WHERE oi.order_id = ANY($1)
+		ORDER BY oi.order_id, i.name`
+
+	rows, err := conn(ctx, q.db).Query(ctx, query, ids)
+	if err != nil {
+		return classify(err, "order", "")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID string
+		orderItem := &api.OrderItem{Item: &api.Item{}}
+		if err := scanItem(prefixScanner{rows, []any{&orderID, &orderItem.Quantity}}, orderItem.Item); err != nil {
+			return classify(err, "order", orderID)
+		}
+		if order, ok := byID[orderID]; ok {
+			order.Items = append(order.Items, orderItem)
+		}
+	}
+
+	return classify(rows.Err(), "order", "")
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// prefixScanner lets a scan helper read a row that starts with extra columns,
+// which are scanned into prefix.
+type prefixScanner struct {
+	row    rowScanner
+	prefix []any
+}
+
+func (s prefixScanner) Scan(dest ...any) error {
+	return s.row.Scan(append(s.prefix, dest...)...)
+}
+
+// scanOrder scans a row selected with orderColumns into order.
+func scanOrder(row rowScanner, order *api.Order) error {
+	var totalPrice int64
+	var currency, paymentStatus string
+	var deletedAt *time.Time
+	var createdAt, updatedAt time.Time
 
-	_, err := tx.Exec(ctx, query, id)
+	err := row.Scan(&order.Id, &order.UserId, &totalPrice, &currency, &paymentStatus, &order.PaymentUrl, &deletedAt, &createdAt, &updatedAt, &order.Version)
 	if err != nil {
 		return err
 	}
 
+	order.TotalPrice = money.New(currency, totalPrice)
+	order.PaymentStatus = payment.Status(paymentStatus).ToProto()
+	order.DeletedAt = timestampOrNil(deletedAt)
+	order.CreatedAt = timestamppb.New(createdAt)
+	order.UpdatedAt = timestamppb.New(updatedAt)
 	return nil
 }
@@ -0,0 +1,90 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OutboxEvent is a serialized domain event waiting in the outbox table.
+// EventType is the full protobuf message name of Payload.
+type OutboxEvent struct {
+	ID            string
+	AggregateType string
+	AggregateID   string
+	EventType     string
+	Payload       []byte
+	CreatedAt     time.Time
+}
+
+type OutboxQuery interface {
+	AppendEvent(ctx context.Context, tx pgx.Tx, event *OutboxEvent) error
+	ListPendingEvents(ctx context.Context, tx pgx.Tx, limit int32) ([]*OutboxEvent, error)
+	MarkEventsDispatched(ctx context.Context, tx pgx.Tx, ids []string) error
+}
+
+type outboxQuery struct {
+	db *pgxpool.Pool
+}
+
+func NewOutboxQuery(db *pgxpool.Pool) *outboxQuery {
+	return &outboxQuery{db: db}
+}
+
+func (q *outboxQuery) AppendEvent(ctx context.Context, tx pgx.Tx, event *OutboxEvent) error {
+	query := `INSERT INTO outbox (id, aggregate_type, aggregate_id, event_type, payload) VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := tx.Exec(ctx, query, event.ID, event.AggregateType, event.AggregateID, event.EventType, event.Payload)
+	if err != nil {
+		return classify(err, "outbox event", event.ID)
+	}
+
+	return nil
+}
+
+// ListPendingEvents locks up to limit undispatched events in creation order.
+// Rows locked by another relay are skipped, so several replicas can relay
+// concurrently without publishing the same event at the same time.
+func (q *outboxQuery) ListPendingEvents(ctx context.Context, tx pgx.Tx, limit int32) ([]*OutboxEvent, error) {
+	query := `SELECT id, aggregate_type, aggregate_id, event_type, payload, created_at
+		FROM outbox
+		WHERE dispatched_at IS NULL
+		ORDER BY created_at, id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		return nil, classify(err, "outbox event", "")
+	}
+	defer rows.Close()
+
+	var events []*OutboxEvent
+	for rows.Next() {
+		var event OutboxEvent
+		err := rows.Scan(&event.ID, &event.AggregateType, &event.AggregateID, &event.EventType, &event.Payload, &event.CreatedAt)
+		if err != nil {
+			return nil, classify(err, "outbox event", "")
+		}
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, classify(err, "outbox event", "")
+	}
+
+	return events, nil
+}
+
+func (q *outboxQuery) MarkEventsDispatched(ctx context.Context, tx pgx.Tx, ids []string) error {
+	query := `UPDATE outbox SET dispatched_at = NOW() WHERE id = ANY($1)`
+
+	_, err := tx.Exec(ctx, query, ids)
+	if err != nil {
+		return classify(err, "outbox event", "")
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package query
+
+const (
+	DefaultPageSize int32 = 20
+	MaxPageSize     int32 = 100
+)
+
+// Page is a normalized, 1-based page request. Use NewPage to build one so the
+// server-side page size limit is always applied.
+//
+// When After is set, queries that support keyset pagination seek past the
+// cursor instead of using Number as an offset.
+type Page struct {
+	Number int32
+	Size   int32
+	After  *Cursor
+}
+
+func NewPage(number, size int32) Page {
+	if number < 1 {
+		number = 1
+	}
+
+	switch {
+	case size <= 0:
+		size = DefaultPageSize
+	case size > MaxPageSize:
+		size = MaxPageSize
+	}
+
+	return Page{Number: number, Size: size}
+}
+
+func (p Page) Limit() int32 {
+	return p.Size
+}
+
+// Offset is computed in int64 because (Number-1)*Size overflows int32 for
+// large page numbers.
+func (p Page) Offset() int64 {
+	if p.After != nil {
+		return 0
+	}
+	return int64(p.Number-1) * int64(p.Size)
+}
@@ -0,0 +1,49 @@
+package query
+
+import "testing"
+
+func TestNewPage(t *testing.T) {
+	tests := []struct {
+		name         string
+		number, size int32
+		want         Page
+	}{
+		{"defaults", 0, 0, Page{Number: 1, Size: DefaultPageSize}},
+		{"negative", -3, -1, Page{Number: 1, Size: DefaultPageSize}},
+		{"in range", 4, 10, Page{Number: 4, Size: 10}},
+		{"size capped", 2, MaxPageSize + 1, Page{Number: 2, Size: MaxPageSize}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewPage(tt.number, tt.size); got != tt.want {
+				t.Errorf("NewPage(%d, %d) = %+v, want %+v", tt.number, tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageOffset(t *testing.T) {
+	tests := []struct {
+		name string
+		page Page
+		want int64
+	}{
+		{"first page", NewPage(1, 20), 0},
+		{"third page", NewPage(3, 20), 40},
+		{"beyond int32", NewPage(1<<30, 100), (1<<30 - 1) * 100},
+		{"largest page", NewPage(1<<31-1, MaxPageSize), (1<<31 - 2) * int64(MaxPageSize)},
+		{"after cursor", Page{Number: 5, Size: 20, After: &Cursor{Key: "a", ID: "b"}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.page.Offset(); got != tt.want {
+				t.Errorf("Offset() = %d, want %d", got, tt.want)
+			}
+			if got := tt.page.Limit(); got != tt.page.Size {
+				t.Errorf("Limit() = %d, want %d", got, tt.page.Size)
+			}
+		})
+	}
+}
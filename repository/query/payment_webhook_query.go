@@ -0,0 +1,35 @@
+package query
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PaymentWebhookQuery interface {
+	RecordWebhookEvent(ctx context.Context, tx pgx.Tx, eventID, orderID, status string) (bool, error)
+}
+
+type paymentWebhookQuery struct {
+	db *pgxpool.Pool
+}
+
+func NewPaymentWebhookQuery(db *pgxpool.Pool) *paymentWebhookQuery {
+	return &paymentWebhookQuery{db: db}
+}
+
+// RecordWebhookEvent stores the ID of a processed webhook notification. It
+// returns false when the ID was already recorded, i.e. the notification is a
+// replay.
+func (q *paymentWebhookQuery) RecordWebhookEvent(ctx context.Context, tx pgx.Tx, eventID, orderID, status string) (bool, error) {
+	query := `INSERT INTO payment_webhook_events (event_id, order_id, payment_status) VALUES ($1, $2, $3)
+		ON CONFLICT (event_id) DO NOTHING`
+
+	tag, err := tx.Exec(ctx, query, eventID, orderID, status)
+	if err != nil {
+		return false, classify(err, "payment webhook event", eventID)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
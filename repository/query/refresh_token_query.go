@@ -0,0 +1,71 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+)
+
+type RefreshTokenQuery interface {
+	CreateRefreshToken(ctx context.Context, tx pgx.Tx, id, userID string, expiresAt time.Time) error
+	RevokeRefreshToken(ctx context.Context, tx pgx.Tx, id, userID string) error
+	DeleteUserRefreshTokens(ctx context.Context, tx pgx.Tx, userID string) error
+}
+
+type refreshTokenQuery struct {
+	db *pgxpool.Pool
+}
+
+func NewRefreshTokenQuery(db *pgxpool.Pool) *refreshTokenQuery {
+	return &refreshTokenQuery{db: db}
+}
+
+func (q *refreshTokenQuery) CreateRefreshToken(ctx context.Context, tx pgx.Tx, id, userID string, expiresAt time.Time) error {
+	query := `INSERT INTO refresh_tokens (id, user_id, expires_at) VALUES ($1, $2, $3)`
+
+	_, err := tx.Exec(ctx, query, id, userID, expiresAt)
+	if err != nil {
+		if pgCode(err) == codeForeignKeyViolation {
+			return domainerr.ResourceNotFound("user", userID).WithCause(err)
+		}
+		return classify(err, "refresh token", id)
+	}
+
+	return nil
+}
+
+// RevokeRefreshToken marks an active refresh token as revoked. It returns
+// a NotFound error when the token does not exist, belongs to another user, has
+// expired or was already revoked, which makes revocation single-use.
+func (q *refreshTokenQuery) RevokeRefreshToken(ctx context.Context, tx pgx.Tx, id, userID string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()`
+
+	tag, err := tx.Exec(ctx, query, id, userID)
+	if err != nil {
+		return classify(err, "refresh token", id)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domainerr.ForResource(domainerr.NotFound, "refresh token", id, "not found or no longer active")
+	}
+
+	return nil
+}
+
+// DeleteUserRefreshTokens removes every refresh token issued to userID, so
+// none of them can be exchanged for new tokens anymore.
+func (q *refreshTokenQuery) DeleteUserRefreshTokens(ctx context.Context, tx pgx.Tx, userID string) error {
+	query := `DELETE FROM refresh_tokens WHERE user_id = $1`
+
+	_, err := tx.Exec(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReplicationLag returns how long ago db, a streaming replica, replayed the
+// last transaction it received from the primary. It is zero when db has
+// replayed everything it received, so an idle primary does not count as lag,
+// and when db is not a replica at all.
+func ReplicationLag(ctx context.Context, db *pgxpool.Pool) (time.Duration, error) {
+	query := `
+		SELECT CASE
+			WHEN NOT pg_is_in_recovery() THEN 0
+			WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+			ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+		END::float8`
+
+	var seconds *float64
+	if err := db.QueryRow(ctx, query).Scan(&seconds); err != nil {
+		return 0, fmt.Errorf("failed to query replication lag: %w", err)
+	}
+	if seconds == nil {
+		return 0, errors.New("replica has not replayed any transaction yet")
+	}
+
+	return time.Duration(*seconds * float64(time.Second)), nil
+}
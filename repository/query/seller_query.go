@@ -2,21 +2,29 @@ package query
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
 	"github.com/daffaromero/gorpc-template/protobuf/api"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type SellerQuery interface {
 	CreateSeller(ctx context.Context, tx pgx.Tx, seller *api.Seller) (*api.Seller, error)
 	GetSeller(ctx context.Context, id string) (*api.Seller, error)
-	ListSellers(ctx context.Context) ([]*api.Seller, error)
+	ListSellers(ctx context.Context, page Page) ([]*api.Seller, error)
+	CountSellers(ctx context.Context) (int32, error)
 	UpdateSeller(ctx context.Context, tx pgx.Tx, seller *api.Seller) (*api.Seller, error)
 	DeleteSeller(ctx context.Context, tx pgx.Tx, id string) error
 }
 
+// sellerColumns is the column list read by scanSeller.
+const sellerColumns = `id, name, COALESCE(owner_id::text, ''), created_at, updated_at, version`
+
 type sellerQuery struct {
 	db *pgxpool.Pool
 }
@@ -26,59 +34,81 @@ func NewSellerQuery(db *pgxpool.Pool) *sellerQuery {
 }
 
 func (q *sellerQuery) CreateSeller(ctx context.Context, tx pgx.Tx, seller *api.Seller) (*api.Seller, error) {
-	query := `INSERT INTO sellers (id, name) VALUES ($1, $2) RETURNING id, name`
+	query := `INSERT INTO sellers (id, name, owner_id) VALUES ($1, $2, NULLIF($3, '')::uuid)
+		RETURNING ` + sellerColumns
 
 	var createdSeller api.Seller
-	err := tx.QueryRow(ctx, query, seller.Id, seller.Name).Scan(&createdSeller.Id, &createdSeller.Name)
+	err := scanSeller(tx.QueryRow(ctx, query, seller.Id, seller.Name, seller.OwnerId), &createdSeller)
 	if err != nil {
-		return nil, err
+		if pgCode(err) == codeForeignKeyViolation {
+			return nil, domainerr.ResourceNotFound("user", seller.OwnerId).WithCause(err)
+		}
+		return nil, classify(err, "seller", seller.Id)
 	}
 
 	return &createdSeller, nil
 }
 
 func (q *sellerQuery) GetSeller(ctx context.Context, id string) (*api.Seller, error) {
-	query := `SELECT id, name FROM sellers WHERE id = $1`
+	query := `SELECT ` + sellerColumns + ` FROM sellers WHERE id = $1`
 
-	row := q.db.QueryRow(ctx, query, id)
+	row := conn(ctx, q.db).QueryRow(ctx, query, id)
 
 	var seller api.Seller
-	err := row.Scan(&seller.Id, &seller.Name)
+	err := scanSeller(row, &seller)
 	if err != nil {
-		return nil, err
+		return nil, classify(err, "seller", id)
 	}
 
 	return &seller, nil
 }
 
-func (q *sellerQuery) ListSellers(ctx context.Context) ([]*api.Seller, error) {
-	query := `SELECT id, name FROM sellers`
+func (q *sellerQuery) ListSellers(ctx context.Context, page Page) ([]*api.Seller, error) {
+	query := `SELECT ` + sellerColumns + ` FROM sellers ORDER BY name, id LIMIT $1 OFFSET $2`
 
-	rows, err := q.db.Query(ctx, query)
+	rows, err := conn(ctx, q.db).Query(ctx, query, page.Limit(), page.Offset())
 	if err != nil {
-		return nil, err
+		return nil, classify(err, "seller", "")
 	}
+	defer rows.Close()
 
 	var sellers []*api.Seller
 	for rows.Next() {
 		var seller api.Seller
-		err := rows.Scan(&seller.Id, &seller.Name)
+		err := scanSeller(rows, &seller)
 		if err != nil {
-			return nil, err
+			return nil, classify(err, "seller", "")
 		}
 		sellers = append(sellers, &seller)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, classify(err, "seller", "")
+	}
 
 	return sellers, nil
 }
 
+func (q *sellerQuery) CountSellers(ctx context.Context) (int32, error) {
+	query := `SELECT COUNT(*) FROM sellers`
+
+	var count int32
+	if err := conn(ctx, q.db).QueryRow(ctx, query).Scan(&count); err != nil {
+		return 0, classify(err, "seller", "")
+	}
+
+	return count, nil
+}
+
 func (q *sellerQuery) UpdateSeller(ctx context.Context, tx pgx.Tx, seller *api.Seller) (*api.Seller, error) {
-	query := `UPDATE sellers SET name = $1 WHERE id = $2 RETURNING id, name`
+	query := `UPDATE sellers SET name = $1 WHERE id = $2 AND version = $3 RETURNING ` + sellerColumns
 
 	var updatedSeller api.Seller
-	err := tx.QueryRow(ctx, query, seller.Name, seller.Id).Scan(&updatedSeller.Id, &updatedSeller.Name)
+	err := scanSeller(tx.QueryRow(ctx, query, seller.Name, seller.Id, seller.Version), &updatedSeller)
 	if err != nil {
-		return nil, err
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, versionConflict(ctx, tx, "sellers", "", "seller", seller.Id)
+		}
+		return nil, classify(err, "seller", seller.Id)
 	}
 
 	return &updatedSeller, nil
@@ -87,10 +117,29 @@ func (q *sellerQuery) UpdateSeller(ctx context.Context, tx pgx.Tx, seller *api.S
 func (q *sellerQuery) DeleteSeller(ctx context.Context, tx pgx.Tx, id string) error {
 	query := `DELETE FROM sellers WHERE id = $1`
 
-	_, err := tx.Exec(ctx, query, id)
+	tag, err := tx.Exec(ctx, query, id)
 	if err != nil {
+		if pgCode(err) == codeForeignKeyViolation {
+			return domainerr.ForResource(domainerr.FailedPrecondition, "seller", id, "still has items").WithCause(err)
+		}
+		return classify(err, "seller", id)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domainerr.ResourceNotFound("seller", id)
+	}
+
+	return nil
+}
+
+func scanSeller(row rowScanner, seller *api.Seller) error {
+	var createdAt, updatedAt time.Time
+
+	if err := row.Scan(&seller.Id, &seller.Name, &seller.OwnerId, &createdAt, &updatedAt, &seller.Version); err != nil {
 		return err
 	}
 
+	seller.CreatedAt = timestamppb.New(createdAt)
+	seller.UpdatedAt = timestamppb.New(updatedAt)
 	return nil
 }
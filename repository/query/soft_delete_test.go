@@ -0,0 +1,88 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+)
+
+func TestSoftDelete(t *testing.T) {
+	tests := []struct {
+		name   string
+		delete func(tx *fakeTx) error
+	}{
+		{"user", func(tx *fakeTx) error { return NewUserQuery(nil).DeleteUser(context.Background(), tx, "id-1") }},
+		{"order", func(tx *fakeTx) error { return NewOrderQuery(nil).DeleteOrder(context.Background(), tx, "id-1") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &fakeTx{rowsAffected: 1}
+			if err := tt.delete(tx); err != nil {
+				t.Fatalf("delete = %v", err)
+			}
+			if len(tx.queries) != 1 || !strings.Contains(tx.queries[0], "SET deleted_at = NOW()") || !strings.Contains(tx.queries[0], "deleted_at IS NULL") {
+				t.Errorf("queries = %q, want a soft delete of a live row", tx.queries)
+			}
+
+			// Deleting a missing or already deleted row matches nothing.
+			if err := tt.delete(&fakeTx{}); !errors.Is(err, domainerr.NotFound) {
+				t.Errorf("delete of a deleted row = %v, want NotFound", err)
+			}
+		})
+	}
+}
+
+func TestRestoreNotDeleted(t *testing.T) {
+	tx := &fakeTx{}
+
+	_, err := NewUserQuery(nil).RestoreUser(context.Background(), tx, "user-1")
+	if !errors.Is(err, domainerr.NotFound) {
+		t.Errorf("RestoreUser() = %v, want NotFound", err)
+	}
+
+	_, err = NewOrderQuery(nil).RestoreOrder(context.Background(), tx, "order-1")
+	if !errors.Is(err, domainerr.NotFound) {
+		t.Errorf("RestoreOrder() = %v, want NotFound", err)
+	}
+
+	for _, query := range tx.queries {
+		if !strings.Contains(query, "SET deleted_at = NULL") || !strings.Contains(query, "deleted_at IS NOT NULL") {
+			t.Errorf("query %q does not restore a deleted row", query)
+		}
+	}
+}
+
+func TestUpdateOrderMissing(t *testing.T) {
+	tests := []struct {
+		name        string
+		userExists  bool
+		want        string
+		wantQueries int
+	}{
+		{"deleted user", false, "user", 2},
+		{"deleted order", true, "order", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The UPDATE matches no row, then the user lookup answers and,
+			// for an existing user, the version lookup finds no order.
+			tx := &fakeTx{rows: []fakeRow{{}, {value: tt.userExists}}}
+
+			_, err := NewOrderQuery(nil).UpdateOrder(context.Background(), tx, &api.Order{Id: "order-1", UserId: "user-1"})
+
+			var domainErr *domainerr.Error
+			if !errors.As(err, &domainErr) || domainErr.Kind != domainerr.NotFound || domainErr.Resource == nil || domainErr.Resource.Type != tt.want {
+				t.Fatalf("UpdateOrder() = %v, want %s not found", err, tt.want)
+			}
+			if len(tx.queries) != tt.wantQueries || !strings.Contains(tx.queries[0], "FROM users WHERE id = $1 AND deleted_at IS NULL") {
+				t.Errorf("queries = %q", tx.queries)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+)
+
+// scanFunc adapts a function to rowScanner.
+type scanFunc func(dest ...any) error
+
+func (f scanFunc) Scan(dest ...any) error {
+	return f(dest...)
+}
+
+func TestTimestampOrNil(t *testing.T) {
+	if got := timestampOrNil(nil); got != nil {
+		t.Errorf("timestampOrNil(nil) = %v, want nil", got)
+	}
+
+	deletedAt := time.Date(2024, 5, 1, 10, 0, 0, 500, time.UTC)
+	if got := timestampOrNil(&deletedAt); !got.AsTime().Equal(deletedAt) {
+		t.Errorf("timestampOrNil(%v) = %v", deletedAt, got.AsTime())
+	}
+}
+
+func TestScanUserTimestamps(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	updatedAt := createdAt.Add(time.Hour)
+	deletedAt := createdAt.Add(2 * time.Hour)
+
+	tests := []struct {
+		name      string
+		deletedAt *time.Time
+	}{
+		{"live", nil},
+		{"deleted", &deletedAt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := scanFunc(func(dest ...any) error {
+				*dest[3].(**time.Time) = tt.deletedAt
+				*dest[4].(*time.Time) = createdAt
+				*dest[5].(*time.Time) = updatedAt
+				return nil
+			})
+
+			var user api.User
+			if err := scanUser(row, &user); err != nil {
+				t.Fatalf("scanUser() = %v", err)
+			}
+
+			if !user.CreatedAt.AsTime().Equal(createdAt) || !user.UpdatedAt.AsTime().Equal(updatedAt) {
+				t.Errorf("created_at = %v, updated_at = %v", user.CreatedAt.AsTime(), user.UpdatedAt.AsTime())
+			}
+			if (user.DeletedAt != nil) != (tt.deletedAt != nil) {
+				t.Errorf("deleted_at = %v, want set %v", user.DeletedAt, tt.deletedAt != nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package query
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Querier runs statements. *pgxpool.Pool and pgx.Tx both implement it.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type (
+	txKey     struct{}
+	readerKey struct{}
+)
+
+// ContextWithTx returns a copy of ctx that carries tx. Reads made with the
+// returned context run inside tx and see its uncommitted writes.
+func ContextWithTx(ctx context.Context, tx pgx.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext returns the transaction carried by ctx, if any.
+func TxFromContext(ctx context.Context) (pgx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(pgx.Tx)
+	return tx, ok
+}
+
+// ContextWithReader returns a copy of ctx whose reads outside a transaction
+// go to db, usually a read replica, instead of the pool the query was built
+// with.
+func ContextWithReader(ctx context.Context, db *pgxpool.Pool) context.Context {
+	return context.WithValue(ctx, readerKey{}, db)
+}
+
+// conn returns the transaction carried by ctx, or else the reader carried by
+// ctx, or else db.
+func conn(ctx context.Context, db *pgxpool.Pool) Querier {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	if reader, ok := ctx.Value(readerKey{}).(*pgxpool.Pool); ok {
+		return reader
+	}
+	return db
+}
@@ -2,19 +2,38 @@ package query
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
 	"github.com/daffaromero/gorpc-template/protobuf/api"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type UserQuery interface {
 	CreateUser(ctx context.Context, tx pgx.Tx, user *api.User) (*api.User, error)
 	GetUser(ctx context.Context, id string) (*api.User, error)
-	ListUsers(ctx context.Context) ([]*api.User, error)
+	GetCredentials(ctx context.Context, id string) (*Credentials, error)
+	GetCredentialsByEmail(ctx context.Context, email string) (*Credentials, error)
+	ListUsers(ctx context.Context, page Page, showDeleted bool) ([]*api.User, error)
+	CountUsers(ctx context.Context, showDeleted bool) (int32, error)
 	UpdateUser(ctx context.Context, tx pgx.Tx, user *api.User) (*api.User, error)
 	DeleteUser(ctx context.Context, tx pgx.Tx, id string) error
+	RestoreUser(ctx context.Context, tx pgx.Tx, id string) (*api.User, error)
+	PurgeUsers(ctx context.Context, tx pgx.Tx, deletedBefore time.Time) (int64, error)
+}
+
+// userColumns is the column list read by scanUser.
+const userColumns = `id, name, email, deleted_at, created_at, updated_at, version`
+
+// Credentials holds what is needed to authenticate and authorize a user.
+type Credentials struct {
+	UserID       string
+	Role         string
+	PasswordHash string
 }
 
 type userQuery struct {
@@ -26,72 +45,189 @@ func NewUserQuery(db *pgxpool.Pool) *userQuery {
 }
 
 func (q *userQuery) CreateUser(ctx context.Context, tx pgx.Tx, user *api.User) (*api.User, error) {
-	query := `INSERT INTO users (id, name, password) VALUES ($1, $2, $3) RETURNING id, name, password`
+	query := `INSERT INTO users (id, name, email, password) VALUES ($1, $2, $3, $4) RETURNING ` + userColumns
 
 	var createdUser api.User
-	err := tx.QueryRow(ctx, query, user.Id, user.Name, user.Password).Scan(&createdUser.Id, &createdUser.Name, &createdUser.Password)
+	err := scanUser(tx.QueryRow(ctx, query, user.Id, user.Name, user.Email, user.Password), &createdUser)
 	if err != nil {
-		return nil, err
+		if pgCode(err) == codeUniqueViolation {
+			return nil, domainerr.ResourceExists("user", user.Email).WithCause(err)
+		}
+		return nil, classify(err, "user", user.Id)
 	}
 
 	return &createdUser, nil
 }
 
 func (q *userQuery) GetUser(ctx context.Context, id string) (*api.User, error) {
-	query := `SELECT id, name, password FROM users WHERE id = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND deleted_at IS NULL`
 
-	row := q.db.QueryRow(ctx, query, id)
+	row := conn(ctx, q.db).QueryRow(ctx, query, id)
 
 	var user api.User
-	err := row.Scan(&user.Id, &user.Name, &user.Password)
+	err := scanUser(row, &user)
 	if err != nil {
-		return nil, err
+		return nil, classify(err, "user", id)
 	}
 
 	return &user, nil
 }
 
-func (q *userQuery) ListUsers(ctx context.Context) ([]*api.User, error) {
-	query := `SELECT id, name, password FROM users`
+// GetCredentials returns the stored role and password hash of a user. They
+// are kept out of GetUser so the hash never ends up in an api.User.
+func (q *userQuery) GetCredentials(ctx context.Context, id string) (*Credentials, error) {
+	query := `SELECT id, role, password FROM users WHERE id = $1 AND deleted_at IS NULL`
+
+	var credentials Credentials
+	err := conn(ctx, q.db).QueryRow(ctx, query, id).Scan(&credentials.UserID, &credentials.Role, &credentials.PasswordHash)
+	if err != nil {
+		return nil, classify(err, "user", id)
+	}
+
+	return &credentials, nil
+}
+
+// GetCredentialsByEmail is GetCredentials keyed by email, for use during login.
+func (q *userQuery) GetCredentialsByEmail(ctx context.Context, email string) (*Credentials, error) {
+	query := `SELECT id, role, password FROM users WHERE email = $1 AND deleted_at IS NULL`
+
+	var credentials Credentials
+	err := conn(ctx, q.db).QueryRow(ctx, query, email).Scan(&credentials.UserID, &credentials.Role, &credentials.PasswordHash)
+	if err != nil {
+		return nil, classify(err, "user", email)
+	}
+
+	return &credentials, nil
+}
+
+// ListUsers returns one page of users ordered by name. Soft-deleted users are
+// only included when showDeleted is set.
+func (q *userQuery) ListUsers(ctx context.Context, page Page, showDeleted bool) ([]*api.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE ($3 OR deleted_at IS NULL) ORDER BY name, id LIMIT $1 OFFSET $2`
 
-	rows, err := q.db.Query(ctx, query)
+	rows, err := conn(ctx, q.db).Query(ctx, query, page.Limit(), page.Offset(), showDeleted)
 	if err != nil {
-		return nil, err
+		return nil, classify(err, "user", "")
 	}
 	defer rows.Close()
 
 	var users []*api.User
 	for rows.Next() {
 		var user api.User
-		err := rows.Scan(&user.Id, &user.Name, &user.Password)
+		err := scanUser(rows, &user)
 		if err != nil {
-			return nil, err
+			return nil, classify(err, "user", "")
 		}
 		users = append(users, &user)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, classify(err, "user", "")
+	}
 
 	return users, nil
 }
 
+func (q *userQuery) CountUsers(ctx context.Context, showDeleted bool) (int32, error) {
+	query := `SELECT COUNT(*) FROM users WHERE ($1 OR deleted_at IS NULL)`
+
+	var count int32
+	if err := conn(ctx, q.db).QueryRow(ctx, query, showDeleted).Scan(&count); err != nil {
+		return 0, classify(err, "user", "")
+	}
+
+	return count, nil
+}
+
 func (q *userQuery) UpdateUser(ctx context.Context, tx pgx.Tx, user *api.User) (*api.User, error) {
-	query := `UPDATE users SET name = $1, password = $2 WHERE id = $3 RETURNING id, name`
+	// An empty password leaves the stored hash untouched.
+	query := `UPDATE users SET name = $1, email = $2, password = COALESCE(NULLIF($3, ''), password)
+		WHERE id = $4 AND version = $5 AND deleted_at IS NULL
+		RETURNING ` + userColumns
 
 	var updatedUser api.User
-	err := tx.QueryRow(ctx, query, user.Name, user.Password, user.Id).Scan(&updatedUser.Id, &updatedUser.Name)
+	err := scanUser(tx.QueryRow(ctx, query, user.Name, user.Email, user.Password, user.Id, user.Version), &updatedUser)
 	if err != nil {
-		return nil, err
+		if pgCode(err) == codeUniqueViolation {
+			return nil, domainerr.ResourceExists("user", user.Email).WithCause(err)
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, versionConflict(ctx, tx, "users", "deleted_at IS NULL", "user", user.Id)
+		}
+		return nil, classify(err, "user", user.Id)
 	}
 
 	return &updatedUser, nil
 }
 
+// DeleteUser soft-deletes a user. Orders and sellers referring to the user
+// are left alone.
 func (q *userQuery) DeleteUser(ctx context.Context, tx pgx.Tx, id string) error {
-	query := `DELETE FROM users WHERE id = $1`
+	query := `UPDATE users SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
 
-	_, err := tx.Exec(ctx, query, id)
+	tag, err := tx.Exec(ctx, query, id)
 	if err != nil {
+		return classify(err, "user", id)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domainerr.ResourceNotFound("user", id)
+	}
+
+	return nil
+}
+
+func (q *userQuery) RestoreUser(ctx context.Context, tx pgx.Tx, id string) (*api.User, error) {
+	query := `UPDATE users SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL RETURNING ` + userColumns
+
+	var restoredUser api.User
+	err := scanUser(tx.QueryRow(ctx, query, id), &restoredUser)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domainerr.ForResource(domainerr.NotFound, "user", id, "not found among deleted users")
+		}
+		if pgCode(err) == codeUniqueViolation {
+			return nil, domainerr.ForResource(domainerr.AlreadyExists, "user", id, "has an email that is in use again").WithCause(err)
+		}
+		return nil, classify(err, "user", id)
+	}
+
+	return &restoredUser, nil
+}
+
+// PurgeUsers hard-deletes users that were soft-deleted before deletedBefore,
+// together with their refresh tokens. Users that still have orders or own
+// sellers are kept. It returns the number of users removed.
+func (q *userQuery) PurgeUsers(ctx context.Context, tx pgx.Tx, deletedBefore time.Time) (int64, error) {
+	purgeable := `SELECT id FROM users u
+		WHERE u.deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.user_id = u.id)
+			AND NOT EXISTS (SELECT 1 FROM sellers s WHERE s.owner_id = u.id)`
+
+	tokensQuery := `DELETE FROM refresh_tokens WHERE user_id IN (` + purgeable + `)`
+	if _, err := tx.Exec(ctx, tokensQuery, deletedBefore); err != nil {
+		return 0, classify(err, "refresh token", "")
+	}
+
+	query := `DELETE FROM users WHERE id IN (` + purgeable + `)`
+
+	tag, err := tx.Exec(ctx, query, deletedBefore)
+	if err != nil {
+		return 0, classify(err, "user", "")
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func scanUser(row rowScanner, user *api.User) error {
+	var deletedAt *time.Time
+	var createdAt, updatedAt time.Time
+
+	if err := row.Scan(&user.Id, &user.Name, &user.Email, &deletedAt, &createdAt, &updatedAt, &user.Version); err != nil {
 		return err
 	}
 
+	user.DeletedAt = timestampOrNil(deletedAt)
+	user.CreatedAt = timestamppb.New(createdAt)
+	user.UpdatedAt = timestamppb.New(updatedAt)
 	return nil
 }
@@ -0,0 +1,27 @@
+package query
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+)
+
+// versionConflict explains why an update of the row id in table, made
+// conditional on the client's version, matched no row. It returns NotFound
+// when the row is missing or excluded by filter, e.g. "deleted_at IS NULL",
+// and a VersionMismatch carrying the current version otherwise.
+func versionConflict(ctx context.Context, tx pgx.Tx, table, filter, resourceType, id string) error {
+	query := `SELECT version FROM ` + table + ` WHERE id = $1`
+	if filter != "" {
+		query += ` AND ` + filter
+	}
+
+	var current int64
+	if err := tx.QueryRow(ctx, query, id).Scan(&current); err != nil {
+		return classify(err, resourceType, id)
+	}
+
+	return domainerr.VersionMismatch(resourceType, id, current)
+}
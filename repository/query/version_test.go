@@ -0,0 +1,126 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+)
+
+// fakeTx answers each QueryRow with the next of rows, and with
+// pgx.ErrNoRows once they run out. Exec reports rowsAffected rows. It records
+// the statements it ran.
+type fakeTx struct {
+	pgx.Tx
+	rows         []fakeRow
+	rowsAffected int64
+	queries      []string
+}
+
+func (tx *fakeTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tx.queries = append(tx.queries, sql)
+	return pgconn.NewCommandTag(fmt.Sprintf("UPDATE %d", tx.rowsAffected)), nil
+}
+
+func (tx *fakeTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	tx.queries = append(tx.queries, sql)
+	if len(tx.rows) == 0 {
+		return fakeRow{}
+	}
+	row := tx.rows[0]
+	tx.rows = tx.rows[1:]
+	return row
+}
+
+// fakeRow holds a single column, or no row when value is nil. A row with
+// err fails to scan with it.
+type fakeRow struct {
+	value any
+	err   error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.value == nil {
+		return pgx.ErrNoRows
+	}
+	reflect.ValueOf(dest[0]).Elem().Set(reflect.ValueOf(r.value))
+	return nil
+}
+
+func TestVersionConflict(t *testing.T) {
+	current := int64(7)
+	tx := &fakeTx{rows: []fakeRow{{value: current}}}
+
+	err := versionConflict(context.Background(), tx, "users", "deleted_at IS NULL", "user", "user-1")
+
+	if !errors.Is(err, domainerr.Conflict) {
+		t.Fatalf("versionConflict() = %v, want a Conflict", err)
+	}
+	var domainErr *domainerr.Error
+	if !errors.As(err, &domainErr) || domainErr.Info == nil {
+		t.Fatalf("versionConflict() = %v, want a domain error with Info", err)
+	}
+	if domainErr.Info.Reason != "VERSION_MISMATCH" || domainErr.Info.Metadata["current_version"] != "7" {
+		t.Errorf("Info = %+v, want VERSION_MISMATCH with current_version 7", domainErr.Info)
+	}
+	if domainErr.Resource == nil || domainErr.Resource.Type != "user" || domainErr.Resource.Name != "user-1" {
+		t.Errorf("Resource = %+v, want user user-1", domainErr.Resource)
+	}
+
+	if len(tx.queries) != 1 || !strings.Contains(tx.queries[0], "FROM users WHERE id = $1 AND deleted_at IS NULL") {
+		t.Errorf("queries = %q", tx.queries)
+	}
+}
+
+func TestVersionConflictMissingRow(t *testing.T) {
+	tx := &fakeTx{}
+
+	err := versionConflict(context.Background(), tx, "sellers", "", "seller", "seller-1")
+
+	if !errors.Is(err, domainerr.NotFound) {
+		t.Fatalf("versionConflict() = %v, want NotFound", err)
+	}
+	if len(tx.queries) != 1 || !strings.HasSuffix(tx.queries[0], "FROM sellers WHERE id = $1") {
+		t.Errorf("queries = %q", tx.queries)
+	}
+}
+
+func TestUpdateSellerStaleVersion(t *testing.T) {
+	current := int64(3)
+	// The conditional UPDATE matches no row, then the lookup finds version 3.
+	tx := &fakeTx{rows: []fakeRow{{}, {value: current}}}
+
+	_, err := NewSellerQuery(nil).UpdateSeller(context.Background(), tx, &api.Seller{Id: "seller-1", Name: "Acme", Version: 2})
+
+	var domainErr *domainerr.Error
+	if !errors.As(err, &domainErr) || domainErr.Kind != domainerr.Conflict || domainErr.Info.Metadata["current_version"] != "3" {
+		t.Fatalf("UpdateSeller() = %v, want a version mismatch with current version 3", err)
+	}
+	if len(tx.queries) != 2 || !strings.Contains(tx.queries[0], "version = $3") {
+		t.Errorf("queries = %q", tx.queries)
+	}
+}
+
+func TestUpdateOrderStaleVersion(t *testing.T) {
+	// The conditional UPDATE matches no row, the user exists and the lookup
+	// finds version 5.
+	tx := &fakeTx{rows: []fakeRow{{}, {value: true}, {value: int64(5)}}}
+
+	_, err := NewOrderQuery(nil).UpdateOrder(context.Background(), tx, &api.Order{Id: "order-1", UserId: "user-1", Version: 4})
+
+	var domainErr *domainerr.Error
+	if !errors.As(err, &domainErr) || domainErr.Kind != domainerr.Conflict || domainErr.Info.Metadata["current_version"] != "5" {
+		t.Fatalf("UpdateOrder() = %v, want a version mismatch with current version 5", err)
+	}
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/daffaromero/gorpc-template/repository/query"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, id, userID string, expiresAt time.Time) error
+	RotateRefreshToken(ctx context.Context, oldID, newID, userID string, expiresAt time.Time) error
+	RevokeRefreshToken(ctx context.Context, id, userID string) error
+}
+
+type refreshTokenRepository struct {
+	db                Store
+	refreshTokenQuery query.RefreshTokenQuery
+}
+
+func NewRefreshTokenRepository(db Store, refreshTokenQuery query.RefreshTokenQuery) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db, refreshTokenQuery: refreshTokenQuery}
+}
+
+func (r *refreshTokenRepository) CreateRefreshToken(ctx context.Context, id, userID string, expiresAt time.Time) error {
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		err := r.refreshTokenQuery.CreateRefreshToken(ctx, tx, id, userID, expiresAt)
+		if err != nil {
+			return fmt.Errorf("failed to create refresh token: %w", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return nil
+}
+
+// RotateRefreshToken revokes oldID and stores newID in the same transaction,
+// so a refresh token can only ever be exchanged once.
+func (r *refreshTokenRepository) RotateRefreshToken(ctx context.Context, oldID, newID, userID string, expiresAt time.Time) error {
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		if err := r.refreshTokenQuery.RevokeRefreshToken(ctx, tx, oldID, userID); err != nil {
+			return fmt.Errorf("failed to revoke refresh token: %w", err)
+		}
+
+		if err := r.refreshTokenQuery.CreateRefreshToken(ctx, tx, newID, userID, expiresAt); err != nil {
+			return fmt.Errorf("failed to create refresh token: %w", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *refreshTokenRepository) RevokeRefreshToken(ctx context.Context, id, userID string) error {
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		err := r.refreshTokenQuery.RevokeRefreshToken(ctx, tx, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to revoke refresh token: %w", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return nil
+}
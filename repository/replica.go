@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	logs "github.com/daffaromero/gorpc-template/helper/logger"
+	"github.com/daffaromero/gorpc-template/repository/query"
+)
+
+// Replica is a read replica that Store.WithoutTx sends reads to while it is
+// healthy, that is while it answers and lags the primary by at most maxLag.
+type Replica struct {
+	db       *pgxpool.Pool
+	maxLag   time.Duration
+	interval time.Duration
+	healthy  atomic.Bool
+	logger   *logs.Log
+}
+
+func NewReplica(db *pgxpool.Pool, maxLag, interval time.Duration) *Replica {
+	return &Replica{
+		db:       db,
+		maxLag:   maxLag,
+		interval: interval,
+		logger:   logs.New("replica"),
+	}
+}
+
+// Run checks the replica on every tick until ctx is cancelled. Reads stay on
+// the primary until the first check passes.
+func (r *Replica) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Replica) check(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, r.interval)
+	defer cancel()
+
+	lag, err := query.ReplicationLag(ctx, r.db)
+	switch {
+	case err != nil:
+		r.setHealthy(false, "Replica unavailable, reading from the primary: %v", err)
+	case lag > r.maxLag:
+		r.setHealthy(false, "Replica is %v behind, reading from the primary", lag)
+	default:
+		r.setHealthy(true, "Replica is %v behind, reading from the replica", lag)
+	}
+}
+
+// setHealthy logs only when the health of the replica changes, so a replica
+// that stays down does not flood the log.
+func (r *Replica) setHealthy(healthy bool, format string, args ...any) {
+	if r.healthy.Swap(healthy) == healthy {
+		return
+	}
+
+	if healthy {
+		r.logger.Info(format, args...)
+	} else {
+		r.logger.Warn(format, args...)
+	}
+}
+
+// markUnhealthy sends reads to the primary until the next check passes.
+func (r *Replica) markUnhealthy(err error) {
+	r.setHealthy(false, "Replica read failed, reading from the primary: %v", err)
+}
+
+func (r *Replica) available() bool {
+	return r != nil && r.healthy.Load()
+}
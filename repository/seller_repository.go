@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository/query"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type SellerRepository interface {
+	CreateSeller(ctx context.Context, seller *api.Seller) (*api.Seller, error)
+	GetSeller(ctx context.Context, id string) (*api.Seller, error)
+	ListSellers(ctx context.Context, page query.Page) ([]*api.Seller, int32, error)
+	UpdateSeller(ctx context.Context, seller *api.Seller) (*api.Seller, error)
+	DeleteSeller(ctx context.Context, id string) error
+	ListSellerItems(ctx context.Context, sellerID string, page query.Page) ([]*api.Item, int32, *query.Cursor, error)
+}
+
+type sellerRepository struct {
+	db          Store
+	sellerQuery query.SellerQuery
+	itemQuery   query.ItemQuery
+	outboxQuery query.OutboxQuery
+}
+
+func NewSellerRepository(db Store, sellerQuery query.SellerQuery, itemQuery query.ItemQuery, outboxQuery query.OutboxQuery) SellerRepository {
+	return &sellerRepository{db: db, sellerQuery: sellerQuery, itemQuery: itemQuery, outboxQuery: outboxQuery}
+}
+
+func (r *sellerRepository) CreateSeller(ctx context.Context, seller *api.Seller) (*api.Seller, error) {
+	var createdSeller *api.Seller
+
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		createdSeller, err = r.sellerQuery.CreateSeller(ctx, tx, seller)
+		if err != nil {
+			return fmt.Errorf("failed to create seller: %w", err)
+		}
+
+		return appendEvent(ctx, tx, r.outboxQuery, sellerAggregate, createdSeller.Id, &api.SellerCreated{Seller: createdSeller})
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return createdSeller, nil
+}
+
+func (r *sellerRepository) GetSeller(ctx context.Context, id string) (*api.Seller, error) {
+	var seller *api.Seller
+
+	err := r.db.WithoutTx(ctx, func(ctx context.Context) error {
+		var err error
+		seller, err = r.sellerQuery.GetSeller(ctx, id)
+		return err
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seller: %w", err)
+	}
+
+	return seller, nil
+}
+
+func (r *sellerRepository) ListSellers(ctx context.Context, page query.Page) ([]*api.Seller, int32, error) {
+	var sellers []*api.Seller
+	var totalCount int32
+
+	err := r.db.WithoutTx(ctx, func(ctx context.Context) error {
+		var err error
+		sellers, err = r.sellerQuery.ListSellers(ctx, page)
+		if err != nil {
+			return err
+		}
+
+		totalCount, err = r.sellerQuery.CountSellers(ctx)
+		return err
+	})
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list sellers: %w", err)
+	}
+	return sellers, totalCount, nil
+}
+
+func (r *sellerRepository) UpdateSeller(ctx context.Context, seller *api.Seller) (*api.Seller, error) {
+	var updatedSeller *api.Seller
+
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		updatedSeller, err = r.sellerQuery.UpdateSeller(ctx, tx, seller)
+		if err != nil {
+			return fmt.Errorf("failed to update seller: %w", err)
+		}
+
+		return appendEvent(ctx, tx, r.outboxQuery, sellerAggregate, updatedSeller.Id, &api.SellerUpdated{Seller: updatedSeller})
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return updatedSeller, nil
+}
+
+func (r *sellerRepository) DeleteSeller(ctx context.Context, id string) error {
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		err := r.sellerQuery.DeleteSeller(ctx, tx, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete seller: %w", err)
+		}
+
+		return appendEvent(ctx, tx, r.outboxQuery, sellerAggregate, id, &api.SellerDeleted{Id: id})
+	})
+
+	if err != nil {
+		return fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return nil
+}
+
+// ListSellerItems returns a page of the items of a seller, or a NotFound
+// error if the seller does not exist. Like ListItems, the total count is only
+// computed for offset pages.
+func (r *sellerRepository) ListSellerItems(ctx context.Context, sellerID string, page query.Page) ([]*api.Item, int32, *query.Cursor, error) {
+	var items []*api.Item
+	var totalCount int32
+	var next *query.Cursor
+
+	err := r.db.WithoutTx(ctx, func(ctx context.Context) error {
+		if _, err := r.sellerQuery.GetSeller(ctx, sellerID); err != nil {
+			return err
+		}
+
+		var err error
+		items, next, err = r.itemQuery.ListSellerItems(ctx, sellerID, page)
+		if err != nil || page.After != nil {
+			return err
+		}
+
+		totalCount, err = r.itemQuery.CountSellerItems(ctx, sellerID)
+		return err
+	})
+
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to list seller items: %w", err)
+	}
+	return items, totalCount, next, nil
+}
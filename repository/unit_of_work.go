@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// UnitOfWork groups the writes of several repositories into one transaction.
+type UnitOfWork interface {
+	// Do runs fn in a transaction. Repository methods called with the context
+	// passed to fn join that transaction, so either all of their writes and
+	// outbox events are committed or none are. Like Store.WithTx, fn is run
+	// again when the transaction hits a serialization failure or deadlock.
+	// Inside another unit of work, Do runs fn in a savepoint, so a failure
+	// only undoes fn's writes and the outer fn may carry on.
+	//
+	// The transaction is serializable unless opts say otherwise, because
+	// repository writes that move stock or payment state require it.
+	Do(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error
+}
+
+type unitOfWork struct {
+	db Store
+}
+
+func NewUnitOfWork(db Store) UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error {
+	err := u.db.WithTx(ctx, func(ctx context.Context, _ pgx.Tx) error {
+		return fn(ctx)
+	}, append([]TxOption{Isolation(pgx.Serializable)}, opts...)...)
+	if err != nil {
+		return fmt.Errorf("unit of work failed: %w", err)
+	}
+
+	return nil
+}
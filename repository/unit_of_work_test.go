@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/daffaromero/gorpc-template/repository/query"
+)
+
+// recordingStore runs fn once in tx and records the settings it was given.
+// WithoutTx runs fn once.
+type recordingStore struct {
+	Store
+	tx       pgx.Tx
+	settings txSettings
+}
+
+func (s *recordingStore) WithTx(ctx context.Context, fn func(ctx context.Context, tx pgx.Tx) error, opts ...TxOption) error {
+	s.settings = txSettings{}
+	for _, opt := range opts {
+		opt(&s.settings)
+	}
+	return fn(query.ContextWithTx(ctx, s.tx), s.tx)
+}
+
+func (s *recordingStore) WithoutTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestUnitOfWorkIsolation(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []TxOption
+		want pgx.TxIsoLevel
+	}{
+		{"serializable by default", nil, pgx.Serializable},
+		{"caller overrides", []TxOption{Isolation(pgx.RepeatableRead)}, pgx.RepeatableRead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &recordingStore{}
+
+			if err := NewUnitOfWork(store).Do(context.Background(), func(ctx context.Context) error { return nil }, tt.opts...); err != nil {
+				t.Fatalf("Do() = %v", err)
+			}
+			if store.settings.options.IsoLevel != tt.want {
+				t.Errorf("isolation = %q, want %q", store.settings.options.IsoLevel, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitOfWorkSharesTx(t *testing.T) {
+	store := &recordingStore{tx: &struct{ pgx.Tx }{}}
+
+	var got pgx.Tx
+	err := NewUnitOfWork(store).Do(context.Background(), func(ctx context.Context) error {
+		got, _ = query.TxFromContext(ctx)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do() = %v", err)
+	}
+
+	if got != store.tx {
+		t.Errorf("fn ran with tx %v, want %v", got, store.tx)
+	}
+}
+
+func TestUnitOfWorkError(t *testing.T) {
+	errFn := errors.New("out of stock")
+
+	err := NewUnitOfWork(&recordingStore{}).Do(context.Background(), func(ctx context.Context) error { return errFn })
+
+	if !errors.Is(err, errFn) {
+		t.Errorf("Do() = %v, want %v", err, errFn)
+	}
+}
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/helper/password"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository/query"
+
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+type UserRepository interface {
+	CreateUser(ctx context.Context, user *api.User) (*api.User, error)
+	GetUser(ctx context.Context, id string) (*api.User, error)
+	ListUsers(ctx context.Context, page query.Page, showDeleted bool) ([]*api.User, int32, error)
+	UpdateUser(ctx context.Context, user *api.User) (*api.User, error)
+	DeleteUser(ctx context.Context, id string) error
+	RestoreUser(ctx context.Context, id string) (*api.User, error)
+	VerifyPassword(ctx context.Context, id string, plaintext string) error
+	Authenticate(ctx context.Context, email string, plaintext string) (string, string, error)
+	GetUserRole(ctx context.Context, id string) (string, error)
+}
+
+type userRepository struct {
+	db                Store
+	userQuery         query.UserQuery
+	refreshTokenQuery query.RefreshTokenQuery
+	outboxQuery       query.OutboxQuery
+	hasher            password.Hasher
+}
+
+func NewUserRepository(db Store, userQuery query.UserQuery, refreshTokenQuery query.RefreshTokenQuery, outboxQuery query.OutboxQuery, hasher password.Hasher) UserRepository {
+	return &userRepository{db: db, userQuery: userQuery, refreshTokenQuery: refreshTokenQuery, outboxQuery: outboxQuery, hasher: hasher}
+}
+
+func (r *userRepository) CreateUser(ctx context.Context, user *api.User) (*api.User, error) {
+	user, err := r.withHashedPassword(user)
+	if err != nil {
+		return nil, err
+	}
+
+	var createdUser *api.User
+
+	err = r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		createdUser, err = r.userQuery.CreateUser(ctx, tx, user)
+		if err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+
+		return appendEvent(ctx, tx, r.outboxQuery, userAggregate, createdUser.Id, &api.UserCreated{User: createdUser})
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return createdUser, nil
+}
+
+func (r *userRepository) GetUser(ctx context.Context, id string) (*api.User, error) {
+	var user *api.User
+
+	err := r.db.WithoutTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = r.userQuery.GetUser(ctx, id)
+		return err
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+func (r *userRepository) ListUsers(ctx context.Context, page query.Page, showDeleted bool) ([]*api.User, int32, error) {
+	var users []*api.User
+	var totalCount int32
+
+	err := r.db.WithoutTx(ctx, func(ctx context.Context) error {
+		var err error
+		users, err = r.userQuery.ListUsers(ctx, page, showDeleted)
+		if err != nil {
+			return err
+		}
+
+		totalCount, err = r.userQuery.CountUsers(ctx, showDeleted)
+		return err
+	})
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	return users, totalCount, nil
+}
+
+func (r *userRepository) UpdateUser(ctx context.Context, user *api.User) (*api.User, error) {
+	user, err := r.withHashedPassword(user)
+	if err != nil {
+		return nil, err
+	}
+
+	var updatedUser *api.User
+
+	err = r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		updatedUser, err = r.userQuery.UpdateUser(ctx, tx, user)
+		if err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+
+		// A new password signs the user out everywhere: refresh tokens
+		// issued for the old one must not outlive it.
+		if user.GetPassword() != "" {
+			if err := r.refreshTokenQuery.DeleteUserRefreshTokens(ctx, tx, updatedUser.Id); err != nil {
+				return fmt.Errorf("failed to delete refresh tokens: %w", err)
+			}
+		}
+
+		return appendEvent(ctx, tx, r.outboxQuery, userAggregate, updatedUser.Id, &api.UserUpdated{User: updatedUser})
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return updatedUser, nil
+}
+
+func (r *userRepository) DeleteUser(ctx context.Context, id string) error {
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		err := r.userQuery.DeleteUser(ctx, tx, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+
+		return appendEvent(ctx, tx, r.outboxQuery, userAggregate, id, &api.UserDeleted{Id: id})
+	})
+
+	if err != nil {
+		return fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *userRepository) RestoreUser(ctx context.Context, id string) (*api.User, error) {
+	var restoredUser *api.User
+
+	err := r.db.WithTx(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		restoredUser, err = r.userQuery.RestoreUser(ctx, tx, id)
+		if err != nil {
+			return fmt.Errorf("failed to restore user: %w", err)
+		}
+
+		return appendEvent(ctx, tx, r.outboxQuery, userAggregate, id, &api.UserRestored{User: restoredUser})
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return restoredUser, nil
+}
+
+// VerifyPassword checks plaintext against the stored hash of the user and
+// returns password.ErrMismatch when they differ.
+func (r *userRepository) VerifyPassword(ctx context.Context, id string, plaintext string) error {
+	credentials, err := r.getCredentials(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return r.hasher.Verify(credentials.PasswordHash, plaintext)
+}
+
+// Authenticate returns the ID and role of the user with the given email if
+// plaintext matches the stored password hash.
+func (r *userRepository) Authenticate(ctx context.Context, email string, plaintext string) (string, string, error) {
+	var credentials *query.Credentials
+
+	err := r.db.WithoutTx(ReadYourWrites(ctx), func(ctx context.Context) error {
+		var err error
+		credentials, err = r.userQuery.GetCredentialsByEmail(ctx, email)
+		return err
+	})
+
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get credentials: %w", err)
+	}
+
+	if err := r.hasher.Verify(credentials.PasswordHash, plaintext); err != nil {
+		return "", "", err
+	}
+
+	return credentials.UserID, credentials.Role, nil
+}
+
+func (r *userRepository) GetUserRole(ctx context.Context, id string) (string, error) {
+	credentials, err := r.getCredentials(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	return credentials.Role, nil
+}
+
+// getCredentials reads from the primary, like Authenticate, so that password
+// changes and deletions take effect immediately.
+func (r *userRepository) getCredentials(ctx context.Context, id string) (*query.Credentials, error) {
+	var credentials *query.Credentials
+
+	err := r.db.WithoutTx(ReadYourWrites(ctx), func(ctx context.Context) error {
+		var err error
+		credentials, err = r.userQuery.GetCredentials(ctx, id)
+		return err
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
+	}
+
+	return credentials, nil
+}
+
+// withHashedPassword returns a copy of user whose password is replaced by its
+// hash, so plain text passwords never reach the query layer. An empty password
+// is kept empty.
+func (r *userRepository) withHashedPassword(user *api.User) (*api.User, error) {
+	if user == nil || user.Password == "" {
+		return user, nil
+	}
+
+	hash, err := r.hasher.Hash(user.Password)
+	if err != nil {
+		if errors.Is(err, password.ErrPasswordTooLong) {
+			return nil, domainerr.Invalid("user.password", "%v", err).WithCause(err)
+		}
+		return nil, err
+	}
+
+	hashed := proto.Clone(user).(*api.User)
+	hashed.Password = hash
+
+	return hashed, nil
+}
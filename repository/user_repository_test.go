@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/helper/password"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository/query"
+)
+
+func TestWithHashedPassword(t *testing.T) {
+	hasher, err := password.NewBcryptHasher(bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("NewBcryptHasher() = %v", err)
+	}
+	r := &userRepository{hasher: hasher}
+
+	user := &api.User{Id: "user-1", Email: "alice@example.com", Password: "secret"}
+	hashed, err := r.withHashedPassword(user)
+	if err != nil {
+		t.Fatalf("withHashedPassword() = %v", err)
+	}
+
+	if user.Password != "secret" {
+		t.Errorf("withHashedPassword() changed the caller's user, password = %q", user.Password)
+	}
+	if hashed.Email != user.Email {
+		t.Errorf("hashed email = %q, want %q", hashed.Email, user.Email)
+	}
+	if err := hasher.Verify(hashed.Password, "secret"); err != nil {
+		t.Errorf("hashed password does not verify: %v", err)
+	}
+}
+
+func TestWithHashedPasswordEmpty(t *testing.T) {
+	r := &userRepository{}
+
+	for _, user := range []*api.User{nil, {Id: "user-1"}} {
+		hashed, err := r.withHashedPassword(user)
+		if err != nil || hashed != user {
+			t.Errorf("withHashedPassword(%v) = %v, %v, want the user unchanged", user, hashed, err)
+		}
+	}
+}
+
+func TestWithHashedPasswordTooLong(t *testing.T) {
+	hasher, err := password.NewBcryptHasher(bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("NewBcryptHasher() = %v", err)
+	}
+	r := &userRepository{hasher: hasher}
+
+	_, err = r.withHashedPassword(&api.User{Password: strings.Repeat("a", 73)})
+
+	if !errors.Is(err, domainerr.InvalidArgument) || !errors.Is(err, password.ErrPasswordTooLong) {
+		t.Errorf("withHashedPassword() = %v, want InvalidArgument wrapping ErrPasswordTooLong", err)
+	}
+}
+
+// fakeUserQuery returns every updated user as it was given.
+type fakeUserQuery struct {
+	query.UserQuery
+}
+
+func (q *fakeUserQuery) UpdateUser(ctx context.Context, tx pgx.Tx, user *api.User) (*api.User, error) {
+	return &api.User{Id: user.Id, Email: user.Email}, nil
+}
+
+// fakeRefreshTokenQuery records the users whose refresh tokens were deleted.
+type fakeRefreshTokenQuery struct {
+	query.RefreshTokenQuery
+	deletedFor []string
+}
+
+func (q *fakeRefreshTokenQuery) DeleteUserRefreshTokens(ctx context.Context, tx pgx.Tx, userID string) error {
+	q.deletedFor = append(q.deletedFor, userID)
+	return nil
+}
+
+func TestUpdateUserRevokesRefreshTokens(t *testing.T) {
+	hasher, err := password.NewBcryptHasher(bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("NewBcryptHasher() = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		password    string
+		wantDeleted bool
+	}{
+		{"password changed", "new secret", true},
+		{"password unchanged", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refreshTokenQuery := &fakeRefreshTokenQuery{}
+			r := NewUserRepository(&recordingStore{}, &fakeUserQuery{}, refreshTokenQuery, &fakeOutboxQuery{}, hasher)
+
+			_, err := r.UpdateUser(context.Background(), &api.User{Id: "user-1", Email: "alice@example.com", Password: tt.password})
+			if err != nil {
+				t.Fatalf("UpdateUser() = %v", err)
+			}
+
+			deleted := len(refreshTokenQuery.deletedFor) == 1 && refreshTokenQuery.deletedFor[0] == "user-1"
+			if deleted != tt.wantDeleted || len(refreshTokenQuery.deletedFor) > 1 {
+				t.Errorf("refresh tokens deleted for %v, want deleted = %v", refreshTokenQuery.deletedFor, tt.wantDeleted)
+			}
+		})
+	}
+}
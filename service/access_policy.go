@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/daffaromero/gorpc-template/helper/auth"
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository"
+)
+
+// NewAccessPolicy returns the access rules for every RPC served by this
+// binary. Admins may call everything that is not public; owner rules let
+// users manage their own resources.
+func NewAccessPolicy(itemRepository repository.ItemRepository, orderRepository repository.OrderRepository, sellerRepository repository.SellerRepository) auth.Policy {
+	public := auth.Rule{Public: true}
+	authenticated := auth.Rule{}
+	adminOnly := auth.Rule{Roles: []string{auth.RoleAdmin}}
+
+	userSelf := auth.Rule{
+		Roles: []string{auth.RoleAdmin},
+		Owner: func(ctx context.Context, req any) (string, error) {
+			switch r := req.(type) {
+			case *api.GetUserRequest:
+				return r.GetId(), nil
+			case *api.UpdateUserRequest:
+				return r.GetUser().GetId(), nil
+			}
+			return "", nil
+		},
+	}
+
+	orderOwner := auth.Rule{
+		Roles: []string{auth.RoleAdmin},
+		Owner: func(ctx context.Context, req any) (string, error) {
+			var id string
+			switch r := req.(type) {
+			case *api.CreateOrderRequest:
+				// New orders may only be placed for the caller.
+				return r.GetOrder().GetUserId(), nil
+			case *api.GetOrderRequest:
+				id = r.GetId()
+			case *api.UpdateOrderRequest:
+				id = r.GetOrder().GetId()
+			default:
+				return "", nil
+			}
+
+			order, err := orderRepository.GetOrder(ctx, id)
+			if err != nil {
+				// Orders are private, so a missing order is reported like
+				// someone else's; otherwise any user could probe for IDs.
+				if errors.Is(err, domainerr.NotFound) {
+					return "", nil
+				}
+				return "", err
+			}
+
+			// Only admins may move an order, with its payment and stock
+			// reservations, to another user.
+			if r, ok := req.(*api.UpdateOrderRequest); ok && r.GetOrder().GetUserId() != order.GetUserId() {
+				return "", nil
+			}
+			return order.GetUserId(), nil
+		},
+	}
+
+	sellerOwner := auth.Rule{
+		Roles: []string{auth.RoleAdmin},
+		Owner: func(ctx context.Context, req any) (string, error) {
+			var id string
+			switch r := req.(type) {
+			case *api.UpdateSellerRequest:
+				id = r.GetSeller().GetId()
+			case *api.DeleteSellerRequest:
+				id = r.GetId()
+			default:
+				return "", nil
+			}
+
+			seller, err := sellerRepository.GetSeller(ctx, id)
+			if err != nil {
+				return "", err
+			}
+			return seller.GetOwnerId(), nil
+		},
+	}
+
+	// itemSeller lets the owner of an item's seller manage its stock. Items
+	// without a seller are managed by admins only.
+	itemSeller := auth.Rule{
+		Roles: []string{auth.RoleAdmin},
+		Owner: func(ctx context.Context, req any) (string, error) {
+			r, ok := req.(*api.AdjustStockRequest)
+			if !ok {
+				return "", nil
+			}
+
+			item, err := itemRepository.GetItem(ctx, r.GetItemId())
+			if err != nil {
+				return "", err
+			}
+			if item.GetSellerId() == "" {
+				return "", nil
+			}
+
+			seller, err := sellerRepository.GetSeller(ctx, item.GetSellerId())
+			if err != nil {
+				return "", err
+			}
+			return seller.GetOwnerId(), nil
+		},
+	}
+
+	return auth.Policy{
+		"/AuthService/Login":   public,
+		"/AuthService/Refresh": public,
+		"/AuthService/Logout":  public,
+
+		"/ItemService/CreateItem":  adminOnly,
+		"/ItemService/GetItem":     public,
+		"/ItemService/ListItems":   public,
+		"/ItemService/UpdateItem":  adminOnly,
+		"/ItemService/DeleteItem":  adminOnly,
+		"/ItemService/AdjustStock": itemSeller,
+
+		"/UserService/CreateUser":  public,
+		"/UserService/GetUser":     userSelf,
+		"/UserService/ListUsers":   adminOnly,
+		"/UserService/UpdateUser":  userSelf,
+		"/UserService/DeleteUser":  adminOnly,
+		"/UserService/RestoreUser": adminOnly,
+
+		"/OrderService/CreateOrder":  orderOwner,
+		"/OrderService/GetOrder":     orderOwner,
+		"/OrderService/ListOrders":   adminOnly,
+		"/OrderService/UpdateOrder":  orderOwner,
+		"/OrderService/DeleteOrder":  adminOnly,
+		"/OrderService/RestoreOrder": adminOnly,
+		// Called by the payment provider; the callback signature is
+		// checked by the service instead.
+		"/OrderService/ConfirmPayment": public,
+
+		"/SellerService/CreateSeller":    authenticated,
+		"/SellerService/GetSeller":       public,
+		"/SellerService/ListSellers":     public,
+		"/SellerService/UpdateSeller":    sellerOwner,
+		"/SellerService/DeleteSeller":    sellerOwner,
+		"/SellerService/ListSellerItems": public,
+	}
+}
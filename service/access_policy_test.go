@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/daffaromero/gorpc-template/helper/auth"
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+)
+
+func TestAccessPolicyOrders(t *testing.T) {
+	orders := &fakeOrderRepository{orders: map[string]*api.Order{
+		"order-1": {Id: "order-1", UserId: "alice"},
+	}}
+	policy := NewAccessPolicy(nil, orders, nil)
+
+	alice := &auth.Principal{UserID: "alice", Role: auth.RoleUser}
+	bob := &auth.Principal{UserID: "bob", Role: auth.RoleUser}
+	admin := &auth.Principal{UserID: "root", Role: auth.RoleAdmin}
+
+	tests := []struct {
+		name      string
+		method    string
+		req       any
+		principal *auth.Principal
+		want      error
+	}{
+		{"create for self", "/OrderService/CreateOrder", &api.CreateOrderRequest{Order: &api.Order{UserId: "alice"}}, alice, nil},
+		{"create for another user", "/OrderService/CreateOrder", &api.CreateOrderRequest{Order: &api.Order{UserId: "bob"}}, alice, auth.ErrPermissionDenied},
+		{"get own order", "/OrderService/GetOrder", &api.GetOrderRequest{Id: "order-1"}, alice, nil},
+		{"get another user's order", "/OrderService/GetOrder", &api.GetOrderRequest{Id: "order-1"}, bob, auth.ErrPermissionDenied},
+		{"get missing order", "/OrderService/GetOrder", &api.GetOrderRequest{Id: "order-2"}, alice, auth.ErrPermissionDenied},
+		{"admin gets missing order", "/OrderService/GetOrder", &api.GetOrderRequest{Id: "order-2"}, admin, nil},
+		{"update own order", "/OrderService/UpdateOrder", &api.UpdateOrderRequest{Order: &api.Order{Id: "order-1", UserId: "alice"}}, alice, nil},
+		{"move own order to another user", "/OrderService/UpdateOrder", &api.UpdateOrderRequest{Order: &api.Order{Id: "order-1", UserId: "bob"}}, alice, auth.ErrPermissionDenied},
+		{"claim another user's order", "/OrderService/UpdateOrder", &api.UpdateOrderRequest{Order: &api.Order{Id: "order-1", UserId: "bob"}}, bob, auth.ErrPermissionDenied},
+		{"admin moves order", "/OrderService/UpdateOrder", &api.UpdateOrderRequest{Order: &api.Order{Id: "order-1", UserId: "bob"}}, admin, nil},
+		{"list orders as user", "/OrderService/ListOrders", &api.ListOrdersRequest{}, alice, auth.ErrPermissionDenied},
+		{"confirm payment without principal", "/OrderService/ConfirmPayment", &api.ConfirmPaymentRequest{}, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Authorize(context.Background(), tt.method, tt.req, tt.principal)
+			if tt.want == nil && err != nil {
+				t.Fatalf("Authorize() = %v, want nil", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("Authorize() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessPolicySellers(t *testing.T) {
+	items := &fakeItemRepository{items: map[string]*api.Item{
+		"item-1": {Id: "item-1", SellerId: "seller-1"},
+		"item-2": {Id: "item-2"},
+	}}
+	sellers := &fakeSellerRepository{sellers: map[string]*api.Seller{
+		"seller-1": {Id: "seller-1", OwnerId: "alice"},
+	}}
+	policy := NewAccessPolicy(items, nil, sellers)
+
+	alice := &auth.Principal{UserID: "alice", Role: auth.RoleUser}
+	bob := &auth.Principal{UserID: "bob", Role: auth.RoleUser}
+	admin := &auth.Principal{UserID: "root", Role: auth.RoleAdmin}
+
+	tests := []struct {
+		name      string
+		method    string
+		req       any
+		principal *auth.Principal
+		want      error
+	}{
+		{"create as any user", "/SellerService/CreateSeller", &api.CreateSellerRequest{}, bob, nil},
+		{"create anonymously", "/SellerService/CreateSeller", &api.CreateSellerRequest{}, nil, auth.ErrUnauthenticated},
+		{"list items anonymously", "/SellerService/ListSellerItems", &api.ListSellerItemsRequest{SellerId: "seller-1"}, nil, nil},
+		{"update own seller", "/SellerService/UpdateSeller", &api.UpdateSellerRequest{Seller: &api.Seller{Id: "seller-1"}}, alice, nil},
+		{"update another user's seller", "/SellerService/UpdateSeller", &api.UpdateSellerRequest{Seller: &api.Seller{Id: "seller-1"}}, bob, auth.ErrPermissionDenied},
+		{"delete as admin", "/SellerService/DeleteSeller", &api.DeleteSellerRequest{Id: "seller-1"}, admin, nil},
+		{"delete missing seller", "/SellerService/DeleteSeller", &api.DeleteSellerRequest{Id: "seller-2"}, alice, domainerr.NotFound},
+		{"adjust stock of own item", "/ItemService/AdjustStock", &api.AdjustStockRequest{ItemId: "item-1"}, alice, nil},
+		{"adjust stock of another seller's item", "/ItemService/AdjustStock", &api.AdjustStockRequest{ItemId: "item-1"}, bob, auth.ErrPermissionDenied},
+		{"adjust stock of an item without seller", "/ItemService/AdjustStock", &api.AdjustStockRequest{ItemId: "item-2"}, alice, auth.ErrPermissionDenied},
+		{"admin adjusts stock", "/ItemService/AdjustStock", &api.AdjustStockRequest{ItemId: "item-2"}, admin, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Authorize(context.Background(), tt.method, tt.req, tt.principal)
+			if tt.want == nil && err != nil {
+				t.Fatalf("Authorize() = %v, want nil", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("Authorize() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/helper/password"
+	"github.com/daffaromero/gorpc-template/helper/token"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository"
+)
+
+type authService struct {
+	api.UnimplementedAuthServiceServer
+	userRepository         repository.UserRepository
+	refreshTokenRepository repository.RefreshTokenRepository
+	tokenManager           token.Manager
+}
+
+func NewAuthService(userRepository repository.UserRepository, refreshTokenRepository repository.RefreshTokenRepository, tokenManager token.Manager) api.AuthServiceServer {
+	return &authService{
+		userRepository:         userRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		tokenManager:           tokenManager,
+	}
+}
+
+func (s *authService) Login(ctx context.Context, req *api.LoginRequest) (*api.LoginResponse, error) {
+	userID, role, err := s.userRepository.Authenticate(ctx, req.GetEmail(), req.GetPassword())
+	if err != nil {
+		// Unknown email and wrong password look the same to the caller.
+		if errors.Is(err, domainerr.NotFound) || errors.Is(err, password.ErrMismatch) {
+			return nil, status.Error(codes.Unauthenticated, "invalid email or password")
+		}
+		return nil, err
+	}
+
+	refreshToken, refreshTokenID, expiresAt, err := s.issueRefreshToken(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokenRepository.CreateRefreshToken(ctx, refreshTokenID, userID, expiresAt); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.tokenPair(userID, role, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.LoginResponse{Tokens: tokens}, nil
+}
+
+func (s *authService) Refresh(ctx context.Context, req *api.RefreshRequest) (*api.RefreshResponse, error) {
+	claims, err := s.tokenManager.ParseRefreshToken(req.GetRefreshToken())
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+	}
+
+	// The role is read again so that role changes apply from the next refresh.
+	role, err := s.userRepository.GetUserRole(ctx, claims.Subject)
+	if err != nil {
+		if errors.Is(err, domainerr.NotFound) {
+			return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+		}
+		return nil, err
+	}
+
+	refreshToken, refreshTokenID, expiresAt, err := s.issueRefreshToken(claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.refreshTokenRepository.RotateRefreshToken(ctx, claims.ID, refreshTokenID, claims.Subject, expiresAt)
+	if err != nil {
+		if errors.Is(err, domainerr.NotFound) {
+			return nil, status.Error(codes.Unauthenticated, "refresh token has been revoked")
+		}
+		return nil, err
+	}
+
+	tokens, err := s.tokenPair(claims.Subject, role, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.RefreshResponse{Tokens: tokens}, nil
+}
+
+func (s *authService) Logout(ctx context.Context, req *api.LogoutRequest) (*api.LogoutResponse, error) {
+	claims, err := s.tokenManager.ParseRefreshToken(req.GetRefreshToken())
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+	}
+
+	// Logging out twice is not an error.
+	err = s.refreshTokenRepository.RevokeRefreshToken(ctx, claims.ID, claims.Subject)
+	if err != nil && !errors.Is(err, domainerr.NotFound) {
+		return nil, err
+	}
+
+	return &api.LogoutResponse{Success: true}, nil
+}
+
+func (s *authService) issueRefreshToken(userID string) (string, string, time.Time, error) {
+	refreshTokenID := uuid.NewString()
+
+	refreshToken, expiresAt, err := s.tokenManager.IssueRefreshToken(userID, refreshTokenID)
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+
+	return refreshToken, refreshTokenID, expiresAt, nil
+}
+
+func (s *authService) tokenPair(userID, role, refreshToken string) (*api.TokenPair, error) {
+	accessToken, _, err := s.tokenManager.IssueAccessToken(userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.tokenManager.AccessTTL().Seconds()),
+	}, nil
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/helper/password"
+	"github.com/daffaromero/gorpc-template/helper/token"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository"
+)
+
+// fakeUserRepository knows a single user, alice@example.com with password
+// "secret". Other methods are not used by the auth service and panic.
+type fakeUserRepository struct {
+	repository.UserRepository
+	role string
+}
+
+func (r *fakeUserRepository) Authenticate(ctx context.Context, email, plaintext string) (string, string, error) {
+	if email != "alice@example.com" {
+		return "", "", domainerr.ResourceNotFound("user", email)
+	}
+	if plaintext != "secret" {
+		return "", "", password.ErrMismatch
+	}
+	return "alice", r.role, nil
+}
+
+func (r *fakeUserRepository) GetUserRole(ctx context.Context, id string) (string, error) {
+	if id != "alice" {
+		return "", domainerr.ResourceNotFound("user", id)
+	}
+	return r.role, nil
+}
+
+// fakeRefreshTokenRepository keeps the IDs of live refresh tokens.
+type fakeRefreshTokenRepository struct {
+	live map[string]string
+}
+
+func (r *fakeRefreshTokenRepository) CreateRefreshToken(ctx context.Context, id, userID string, expiresAt time.Time) error {
+	r.live[id] = userID
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) RotateRefreshToken(ctx context.Context, oldID, newID, userID string, expiresAt time.Time) error {
+	if err := r.RevokeRefreshToken(ctx, oldID, userID); err != nil {
+		return err
+	}
+	return r.CreateRefreshToken(ctx, newID, userID, expiresAt)
+}
+
+func (r *fakeRefreshTokenRepository) RevokeRefreshToken(ctx context.Context, id, userID string) error {
+	if r.live[id] != userID {
+		return domainerr.ResourceNotFound("refresh token", id)
+	}
+	delete(r.live, id)
+	return nil
+}
+
+func newTestAuthService(t *testing.T) (api.AuthServiceServer, *fakeUserRepository, token.Manager) {
+	t.Helper()
+
+	manager, err := token.NewManager(token.Config{
+		Issuer:        "test",
+		AccessSecret:  []byte("access"),
+		RefreshSecret: []byte("refresh"),
+		AccessTTL:     time.Minute,
+		RefreshTTL:    time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewManager() = %v", err)
+	}
+
+	users := &fakeUserRepository{role: "user"}
+	refreshTokens := &fakeRefreshTokenRepository{live: map[string]string{}}
+	return NewAuthService(users, refreshTokens, manager), users, manager
+}
+
+func TestLogin(t *testing.T) {
+	s, _, manager := newTestAuthService(t)
+
+	resp, err := s.Login(context.Background(), &api.LoginRequest{Email: "alice@example.com", Password: "secret"})
+	if err != nil {
+		t.Fatalf("Login() = %v", err)
+	}
+
+	claims, err := manager.ParseAccessToken(resp.GetTokens().GetAccessToken())
+	if err != nil {
+		t.Fatalf("ParseAccessToken() = %v", err)
+	}
+	if claims.Subject != "alice" || claims.Role != "user" {
+		t.Errorf("access token claims = %+v", claims)
+	}
+	if resp.GetTokens().GetExpiresIn() != 60 {
+		t.Errorf("ExpiresIn = %d, want 60", resp.GetTokens().GetExpiresIn())
+	}
+}
+
+func TestLoginRejectsBadCredentials(t *testing.T) {
+	s, _, _ := newTestAuthService(t)
+
+	for _, req := range []*api.LoginRequest{
+		{Email: "alice@example.com", Password: "wrong"},
+		{Email: "bob@example.com", Password: "secret"},
+	} {
+		_, err := s.Login(context.Background(), req)
+		if status.Code(err) != codes.Unauthenticated || status.Convert(err).Message() != "invalid email or password" {
+			t.Errorf("Login(%s, %s) = %v, want the same Unauthenticated error", req.Email, req.Password, err)
+		}
+	}
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	s, users, manager := newTestAuthService(t)
+
+	login, err := s.Login(context.Background(), &api.LoginRequest{Email: "alice@example.com", Password: "secret"})
+	if err != nil {
+		t.Fatalf("Login() = %v", err)
+	}
+	first := login.GetTokens().GetRefreshToken()
+
+	users.role = "admin"
+	refreshed, err := s.Refresh(context.Background(), &api.RefreshRequest{RefreshToken: first})
+	if err != nil {
+		t.Fatalf("Refresh() = %v", err)
+	}
+
+	claims, err := manager.ParseAccessToken(refreshed.GetTokens().GetAccessToken())
+	if err != nil {
+		t.Fatalf("ParseAccessToken() = %v", err)
+	}
+	if claims.Role != "admin" {
+		t.Errorf("role after refresh = %q, want the new role admin", claims.Role)
+	}
+
+	_, err = s.Refresh(context.Background(), &api.RefreshRequest{RefreshToken: first})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Refresh() with a rotated token = %v, want Unauthenticated", err)
+	}
+
+	if _, err := s.Refresh(context.Background(), &api.RefreshRequest{RefreshToken: refreshed.GetTokens().GetRefreshToken()}); err != nil {
+		t.Errorf("Refresh() with the new token = %v", err)
+	}
+}
+
+func TestRefreshRejectsAccessToken(t *testing.T) {
+	s, _, _ := newTestAuthService(t)
+
+	login, err := s.Login(context.Background(), &api.LoginRequest{Email: "alice@example.com", Password: "secret"})
+	if err != nil {
+		t.Fatalf("Login() = %v", err)
+	}
+
+	_, err = s.Refresh(context.Background(), &api.RefreshRequest{RefreshToken: login.GetTokens().GetAccessToken()})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Refresh() with an access token = %v, want Unauthenticated", err)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	s, _, _ := newTestAuthService(t)
+
+	login, err := s.Login(context.Background(), &api.LoginRequest{Email: "alice@example.com", Password: "secret"})
+	if err != nil {
+		t.Fatalf("Login() = %v", err)
+	}
+	req := &api.LogoutRequest{RefreshToken: login.GetTokens().GetRefreshToken()}
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.Logout(context.Background(), req); err != nil {
+			t.Fatalf("Logout() #%d = %v", i+1, err)
+		}
+	}
+
+	_, err = s.Refresh(context.Background(), &api.RefreshRequest{RefreshToken: req.RefreshToken})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Refresh() after Logout() = %v, want Unauthenticated", err)
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository"
+	"github.com/daffaromero/gorpc-template/repository/query"
+)
+
+type itemService struct {
+	api.UnimplementedItemServiceServer
+	itemRepository repository.ItemRepository
+}
+
+func NewItemService(itemRepository repository.ItemRepository) api.ItemServiceServer {
+	return &itemService{itemRepository: itemRepository}
+}
+
+func (s *itemService) CreateItem(ctx context.Context, req *api.CreateItemRequest) (*api.CreateItemResponse, error) {
+	item := req.GetItem()
+
+	// IDs are always assigned by the server; any client-supplied value is ignored.
+	item.Id = uuid.NewString()
+
+	createdItem, err := s.itemRepository.CreateItem(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.CreateItemResponse{Item: createdItem}, nil
+}
+
+func (s *itemService) GetItem(ctx context.Context, req *api.GetItemRequest) (*api.GetItemResponse, error) {
+	item, err := s.itemRepository.GetItem(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.GetItemResponse{Item: item}, nil
+}
+
+func (s *itemService) ListItems(ctx context.Context, req *api.ListItemsRequest) (*api.ListItemsResponse, error) {
+	page := query.NewPage(req.GetPage(), req.GetPageSize())
+	if req.GetPageToken() != "" {
+		cursor, err := query.DecodeCursor(req.GetPageToken())
+		if err != nil {
+			return nil, err
+		}
+		page.After = cursor
+	}
+
+	items, totalCount, next, err := s.itemRepository.ListItems(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &api.ListItemsResponse{Items: items, TotalCount: totalCount}
+	if next != nil {
+		resp.NextPageToken = next.Encode()
+	}
+
+	return resp, nil
+}
+
+func (s *itemService) UpdateItem(ctx context.Context, req *api.UpdateItemRequest) (*api.UpdateItemResponse, error) {
+	item, err := s.itemRepository.UpdateItem(ctx, req.GetItem())
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.UpdateItemResponse{Item: item}, nil
+}
+
+func (s *itemService) DeleteItem(ctx context.Context, req *api.DeleteItemRequest) (*api.DeleteItemResponse, error) {
+	if err := s.itemRepository.DeleteItem(ctx, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	return &api.DeleteItemResponse{Success: true}, nil
+}
+
+func (s *itemService) AdjustStock(ctx context.Context, req *api.AdjustStockRequest) (*api.AdjustStockResponse, error) {
+	item, err := s.itemRepository.AdjustStock(ctx, req.GetItemId(), req.GetDelta())
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.AdjustStockResponse{Item: item}, nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository"
+	"github.com/daffaromero/gorpc-template/repository/query"
+)
+
+// fakeItemRepository serves GetItem from items, stores created items and
+// reports every item to delete as missing. It records the page asked of
+// ListItems, which returns next as the cursor of the following page.
+type fakeItemRepository struct {
+	repository.ItemRepository
+	items   map[string]*api.Item
+	created []*api.Item
+	page    query.Page
+	next    *query.Cursor
+}
+
+func (r *fakeItemRepository) GetItem(ctx context.Context, id string) (*api.Item, error) {
+	item, ok := r.items[id]
+	if !ok {
+		return nil, domainerr.ResourceNotFound("item", id)
+	}
+	return item, nil
+}
+
+func (r *fakeItemRepository) CreateItem(ctx context.Context, item *api.Item) (*api.Item, error) {
+	r.created = append(r.created, item)
+	return item, nil
+}
+
+func (r *fakeItemRepository) ListItems(ctx context.Context, page query.Page) ([]*api.Item, int32, *query.Cursor, error) {
+	r.page = page
+	return []*api.Item{{Id: "item-1"}}, 7, r.next, nil
+}
+
+func (r *fakeItemRepository) DeleteItem(ctx context.Context, id string) error {
+	return domainerr.ResourceNotFound("item", id)
+}
+
+func TestCreateItemAssignsID(t *testing.T) {
+	items := &fakeItemRepository{}
+	s := NewItemService(items)
+
+	resp, err := s.CreateItem(context.Background(), &api.CreateItemRequest{Item: &api.Item{Id: "chosen-by-client", Name: "Widget"}})
+	if err != nil {
+		t.Fatalf("CreateItem() = %v", err)
+	}
+
+	if _, err := uuid.Parse(resp.GetItem().GetId()); err != nil {
+		t.Errorf("id = %q, want a UUID assigned by the server", resp.GetItem().GetId())
+	}
+	if len(items.created) != 1 || items.created[0].GetName() != "Widget" {
+		t.Errorf("created %v", items.created)
+	}
+}
+
+func TestListItemsPages(t *testing.T) {
+	items := &fakeItemRepository{next: &query.Cursor{Key: "Widget", ID: "item-1"}}
+	s := NewItemService(items)
+
+	resp, err := s.ListItems(context.Background(), &api.ListItemsRequest{Page: 2, PageSize: 10})
+	if err != nil {
+		t.Fatalf("ListItems() = %v", err)
+	}
+	if items.page != query.NewPage(2, 10) {
+		t.Errorf("page = %+v, want page 2 of 10", items.page)
+	}
+	if resp.GetTotalCount() != 7 || resp.GetNextPageToken() != items.next.Encode() {
+		t.Errorf("ListItems() = %v", resp)
+	}
+
+	if _, err := s.ListItems(context.Background(), &api.ListItemsRequest{PageToken: resp.GetNextPageToken()}); err != nil {
+		t.Fatalf("ListItems() with a page token = %v", err)
+	}
+	if items.page.After == nil || *items.page.After != *items.next {
+		t.Errorf("page.After = %v, want %v", items.page.After, items.next)
+	}
+
+	items.next = nil
+	resp, err = s.ListItems(context.Background(), &api.ListItemsRequest{})
+	if err != nil || resp.GetNextPageToken() != "" {
+		t.Errorf("ListItems() on the last page = %v, %v, want no next page token", resp, err)
+	}
+}
+
+func TestListItemsInvalidPageToken(t *testing.T) {
+	_, err := NewItemService(&fakeItemRepository{}).ListItems(context.Background(), &api.ListItemsRequest{PageToken: "not a token!"})
+
+	if !errors.Is(err, query.ErrInvalidPageToken) {
+		t.Errorf("ListItems() = %v, want ErrInvalidPageToken", err)
+	}
+}
+
+func TestDeleteItemError(t *testing.T) {
+	_, err := NewItemService(&fakeItemRepository{}).DeleteItem(context.Background(), &api.DeleteItemRequest{Id: "item-1"})
+
+	// Errors are returned as they are, for the error interceptor to map.
+	if !errors.Is(err, domainerr.NotFound) {
+		t.Errorf("DeleteItem() = %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	logs "github.com/daffaromero/gorpc-template/helper/logger"
+	"github.com/daffaromero/gorpc-template/payment"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository"
+	"github.com/daffaromero/gorpc-template/repository/query"
+)
+
+type orderService struct {
+	api.UnimplementedOrderServiceServer
+	orderRepository repository.OrderRepository
+	paymentProvider payment.Provider
+	logger          *logs.Log
+}
+
+func NewOrderService(orderRepository repository.OrderRepository, paymentProvider payment.Provider) api.OrderServiceServer {
+	return &orderService{orderRepository: orderRepository, paymentProvider: paymentProvider, logger: logs.New("order_service")}
+}
+
+func (s *orderService) CreateOrder(ctx context.Context, req *api.CreateOrderRequest) (*api.CreateOrderResponse, error) {
+	order := req.GetOrder()
+	if err := validateOrderItems(order); err != nil {
+		return nil, err
+	}
+
+	order.Id = uuid.NewString()
+
+	createdOrder, err := s.orderRepository.CreateOrder(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	createdOrder, err = s.startPayment(ctx, createdOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.CreateOrderResponse{Order: createdOrder}, nil
+}
+
+// startPayment starts the payment of a new order with the provider. If the
+// provider cannot be reached the order is kept with a failed payment.
+func (s *orderService) startPayment(ctx context.Context, order *api.Order) (*api.Order, error) {
+	details, err := s.paymentProvider.CreatePayment(ctx, order.GetId(), order.GetTotalPrice())
+	if err != nil {
+		s.logger.Error("Failed to start payment for order %s: %v", order.GetId(), err)
+		if _, err := s.orderRepository.UpdatePaymentStatus(ctx, order.GetId(), "", payment.StatusFailed); err != nil {
+			s.logger.Error("Failed to mark payment of order %s as failed: %v", order.GetId(), err)
+		}
+		return nil, status.Error(codes.Unavailable, "payment provider unavailable")
+	}
+
+	order, err = s.orderRepository.SetPaymentDetails(ctx, order.GetId(), details)
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (s *orderService) GetOrder(ctx context.Context, req *api.GetOrderRequest) (*api.GetOrderResponse, error) {
+	order, err := s.orderRepository.GetOrder(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.GetOrderResponse{Order: order}, nil
+}
+
+func (s *orderService) ListOrders(ctx context.Context, req *api.ListOrdersRequest) (*api.ListOrdersResponse, error) {
+	page := query.NewPage(req.GetPage(), req.GetPageSize())
+	if req.GetPageToken() != "" {
+		cursor, err := query.DecodeCursor(req.GetPageToken())
+		if err != nil {
+			return nil, err
+		}
+		page.After = cursor
+	}
+
+	orders, totalCount, next, err := s.orderRepository.ListOrders(ctx, page, req.GetShowDeleted())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &api.ListOrdersResponse{Orders: orders, TotalCount: totalCount}
+	if next != nil {
+		resp.NextPageToken = next.Encode()
+	}
+
+	return resp, nil
+}
+
+func (s *orderService) UpdateOrder(ctx context.Context, req *api.UpdateOrderRequest) (*api.UpdateOrderResponse, error) {
+	if err := validateOrderItems(req.GetOrder()); err != nil {
+		return nil, err
+	}
+
+	order, err := s.orderRepository.UpdateOrder(ctx, req.GetOrder())
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.UpdateOrderResponse{Order: order}, nil
+}
+
+func (s *orderService) DeleteOrder(ctx context.Context, req *api.DeleteOrderRequest) (*api.DeleteOrderResponse, error) {
+	if err := s.orderRepository.DeleteOrder(ctx, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	return &api.DeleteOrderResponse{Success: true}, nil
+}
+
+func (s *orderService) RestoreOrder(ctx context.Context, req *api.RestoreOrderRequest) (*api.RestoreOrderResponse, error) {
+	order, err := s.orderRepository.RestoreOrder(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.RestoreOrderResponse{Order: order}, nil
+}
+
+// ConfirmPayment applies a payment status callback from the provider. The
+// callback must carry a valid signature and the token issued for the order.
+func (s *orderService) ConfirmPayment(ctx context.Context, req *api.ConfirmPaymentRequest) (*api.ConfirmPaymentResponse, error) {
+	paymentStatus, ok := payment.StatusFromProto(req.GetStatus())
+	if !ok {
+		return nil, domainerr.Invalid("status", "unknown payment status %d", req.GetStatus())
+	}
+
+	callback := &payment.Callback{
+		OrderID:   req.GetOrderId(),
+		Token:     req.GetPaymentToken(),
+		Status:    paymentStatus,
+		Signature: req.GetSignature(),
+	}
+	if err := s.paymentProvider.VerifyCallback(ctx, callback); err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, err
+	}
+
+	order, err := s.orderRepository.UpdatePaymentStatus(ctx, callback.OrderID, callback.Token, callback.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.ConfirmPaymentResponse{Order: order}, nil
+}
+
+// validateOrderItems checks what the rules in api.proto cannot express: that
+// every item appears in only one line of the order. Prices are deliberately
+// not inspected: the total is computed from the items table.
+func validateOrderItems(order *api.Order) error {
+	seen := make(map[string]struct{}, len(order.GetItems()))
+	for i, orderItem := range order.GetItems() {
+		itemID := orderItem.GetItem().GetId()
+		if _, ok := seen[itemID]; ok {
+			return domainerr.Invalid(fmt.Sprintf("order.items[%d].item.id", i), "item %s appears more than once", itemID)
+		}
+		seen[itemID] = struct{}{}
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/helper/money"
+	"github.com/daffaromero/gorpc-template/payment"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository"
+)
+
+// fakeOrderRepository keeps orders in a map and records the payment status
+// changes made to them.
+type fakeOrderRepository struct {
+	repository.OrderRepository
+	orders   map[string]*api.Order
+	statuses []payment.Status
+}
+
+func (r *fakeOrderRepository) CreateOrder(ctx context.Context, order *api.Order) (*api.Order, error) {
+	order.TotalPrice = money.New("IDR", 30000)
+	r.orders[order.Id] = order
+	return order, nil
+}
+
+func (r *fakeOrderRepository) GetOrder(ctx context.Context, id string) (*api.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, domainerr.ResourceNotFound("order", id)
+	}
+	return order, nil
+}
+
+func (r *fakeOrderRepository) SetPaymentDetails(ctx context.Context, orderID string, details *payment.Payment) (*api.Order, error) {
+	order := r.orders[orderID]
+	order.PaymentUrl = details.URL
+	return order, nil
+}
+
+func (r *fakeOrderRepository) UpdatePaymentStatus(ctx context.Context, orderID, token string, status payment.Status) (*api.Order, error) {
+	r.statuses = append(r.statuses, status)
+	return r.GetOrder(ctx, orderID)
+}
+
+// failingProvider cannot be reached.
+type failingProvider struct {
+	payment.Provider
+}
+
+func (failingProvider) CreatePayment(ctx context.Context, orderID string, amount *api.Money) (*payment.Payment, error) {
+	return nil, errors.New("gateway timeout")
+}
+
+func TestValidateOrderItems(t *testing.T) {
+	line := func(itemID string, quantity int32) *api.OrderItem {
+		return &api.OrderItem{Item: &api.Item{Id: itemID}, Quantity: quantity}
+	}
+
+	tests := []struct {
+		name      string
+		items     []*api.OrderItem
+		wantField string
+	}{
+		{"distinct items", []*api.OrderItem{line("a", 1), line("b", 2)}, ""},
+		{"no items", nil, ""},
+		{"repeated item", []*api.OrderItem{line("a", 1), line("b", 1), line("a", 3)}, "order.items[2].item.id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOrderItems(&api.Order{Items: tt.items})
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("validateOrderItems() = %v, want nil", err)
+				}
+				return
+			}
+
+			var domainErr *domainerr.Error
+			if !errors.As(err, &domainErr) || domainErr.Kind != domainerr.InvalidArgument {
+				t.Fatalf("validateOrderItems() = %v, want InvalidArgument", err)
+			}
+			if len(domainErr.Violations) != 1 || domainErr.Violations[0].Field != tt.wantField {
+				t.Errorf("violations = %+v, want %s", domainErr.Violations, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestCreateOrderStartsPayment(t *testing.T) {
+	orders := &fakeOrderRepository{orders: map[string]*api.Order{}}
+	s := NewOrderService(orders, payment.NewFakeProvider("https://pay.example.com", "secret"))
+
+	resp, err := s.CreateOrder(context.Background(), &api.CreateOrderRequest{Order: &api.Order{UserId: "alice", Items: []*api.OrderItem{
+		{Item: &api.Item{Id: "item-1"}, Quantity: 2},
+	}}})
+	if err != nil {
+		t.Fatalf("CreateOrder() = %v", err)
+	}
+
+	if resp.GetOrder().GetPaymentUrl() == "" {
+		t.Error("order has no payment URL")
+	}
+	if len(orders.statuses) != 0 {
+		t.Errorf("payment moved to %v, want it left pending", orders.statuses)
+	}
+}
+
+func TestCreateOrderProviderUnavailable(t *testing.T) {
+	orders := &fakeOrderRepository{orders: map[string]*api.Order{}}
+	s := NewOrderService(orders, failingProvider{})
+
+	_, err := s.CreateOrder(context.Background(), &api.CreateOrderRequest{Order: &api.Order{UserId: "alice"}})
+
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("CreateOrder() = %v, want Unavailable", err)
+	}
+	if len(orders.statuses) != 1 || orders.statuses[0] != payment.StatusFailed {
+		t.Errorf("payment moved to %v, want failed", orders.statuses)
+	}
+}
+
+func TestConfirmPayment(t *testing.T) {
+	provider := payment.NewFakeProvider("https://pay.example.com", "secret")
+	orders := &fakeOrderRepository{orders: map[string]*api.Order{"order-1": {Id: "order-1"}}}
+	s := NewOrderService(orders, provider)
+
+	callback := &payment.Callback{OrderID: "order-1", Token: "tok", Status: payment.StatusPaid}
+	provider.Sign(callback)
+
+	req := &api.ConfirmPaymentRequest{
+		OrderId:      callback.OrderID,
+		PaymentToken: callback.Token,
+		Status:       api.PaymentStatus_PAYMENT_STATUS_PAID,
+		Signature:    callback.Signature,
+	}
+	if _, err := s.ConfirmPayment(context.Background(), req); err != nil {
+		t.Fatalf("ConfirmPayment() = %v", err)
+	}
+	if len(orders.statuses) != 1 || orders.statuses[0] != payment.StatusPaid {
+		t.Errorf("payment moved to %v, want paid", orders.statuses)
+	}
+
+	// A signature for another status does not authorize this one.
+	req.Status = api.PaymentStatus_PAYMENT_STATUS_FAILED
+	if _, err := s.ConfirmPayment(context.Background(), req); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ConfirmPayment() with a forged status = %v, want PermissionDenied", err)
+	}
+
+	req.Status = api.PaymentStatus_PAYMENT_STATUS_UNSPECIFIED
+	if _, err := s.ConfirmPayment(context.Background(), req); !errors.Is(err, domainerr.InvalidArgument) {
+		t.Errorf("ConfirmPayment() with no status = %v, want InvalidArgument", err)
+	}
+	if len(orders.statuses) != 1 {
+		t.Errorf("payment moved to %v, want only the signed change", orders.statuses)
+	}
+}
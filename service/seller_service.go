@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/daffaromero/gorpc-template/helper/auth"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository"
+	"github.com/daffaromero/gorpc-template/repository/query"
+)
+
+type sellerService struct {
+	api.UnimplementedSellerServiceServer
+	sellerRepository repository.SellerRepository
+}
+
+func NewSellerService(sellerRepository repository.SellerRepository) api.SellerServiceServer {
+	return &sellerService{sellerRepository: sellerRepository}
+}
+
+func (s *sellerService) CreateSeller(ctx context.Context, req *api.CreateSellerRequest) (*api.CreateSellerResponse, error) {
+	seller := req.GetSeller()
+
+	principal := auth.FromContext(ctx)
+	if principal == nil {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	seller.Id = uuid.NewString()
+	seller.OwnerId = principal.UserID
+
+	createdSeller, err := s.sellerRepository.CreateSeller(ctx, seller)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.CreateSellerResponse{Seller: createdSeller}, nil
+}
+
+func (s *sellerService) GetSeller(ctx context.Context, req *api.GetSellerRequest) (*api.GetSellerResponse, error) {
+	seller, err := s.sellerRepository.GetSeller(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.GetSellerResponse{Seller: seller}, nil
+}
+
+func (s *sellerService) ListSellers(ctx context.Context, req *api.ListSellersRequest) (*api.ListSellersResponse, error) {
+	sellers, totalCount, err := s.sellerRepository.ListSellers(ctx, query.NewPage(req.GetPage(), req.GetPageSize()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.ListSellersResponse{Sellers: sellers, TotalCount: totalCount}, nil
+}
+
+func (s *sellerService) UpdateSeller(ctx context.Context, req *api.UpdateSellerRequest) (*api.UpdateSellerResponse, error) {
+	seller, err := s.sellerRepository.UpdateSeller(ctx, req.GetSeller())
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.UpdateSellerResponse{Seller: seller}, nil
+}
+
+func (s *sellerService) DeleteSeller(ctx context.Context, req *api.DeleteSellerRequest) (*api.DeleteSellerResponse, error) {
+	if err := s.sellerRepository.DeleteSeller(ctx, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	return &api.DeleteSellerResponse{Success: true}, nil
+}
+
+func (s *sellerService) ListSellerItems(ctx context.Context, req *api.ListSellerItemsRequest) (*api.ListSellerItemsResponse, error) {
+	page := query.NewPage(req.GetPage(), req.GetPageSize())
+	if req.GetPageToken() != "" {
+		cursor, err := query.DecodeCursor(req.GetPageToken())
+		if err != nil {
+			return nil, err
+		}
+		page.After = cursor
+	}
+
+	items, totalCount, next, err := s.sellerRepository.ListSellerItems(ctx, req.GetSellerId(), page)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &api.ListSellerItemsResponse{Items: items, TotalCount: totalCount}
+	if next != nil {
+		resp.NextPageToken = next.Encode()
+	}
+
+	return resp, nil
+}
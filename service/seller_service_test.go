@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/daffaromero/gorpc-template/helper/auth"
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository"
+	"github.com/daffaromero/gorpc-template/repository/query"
+)
+
+// fakeSellerRepository serves sellers from a map and records the page asked
+// of ListSellerItems, which returns next as the cursor of the following page.
+type fakeSellerRepository struct {
+	repository.SellerRepository
+	sellers map[string]*api.Seller
+	page    query.Page
+	next    *query.Cursor
+}
+
+func (r *fakeSellerRepository) CreateSeller(ctx context.Context, seller *api.Seller) (*api.Seller, error) {
+	r.sellers[seller.Id] = seller
+	return seller, nil
+}
+
+func (r *fakeSellerRepository) GetSeller(ctx context.Context, id string) (*api.Seller, error) {
+	seller, ok := r.sellers[id]
+	if !ok {
+		return nil, domainerr.ResourceNotFound("seller", id)
+	}
+	return seller, nil
+}
+
+func (r *fakeSellerRepository) ListSellerItems(ctx context.Context, sellerID string, page query.Page) ([]*api.Item, int32, *query.Cursor, error) {
+	if _, ok := r.sellers[sellerID]; !ok {
+		return nil, 0, nil, domainerr.ResourceNotFound("seller", sellerID)
+	}
+	r.page = page
+	return []*api.Item{{Id: "item-1", SellerId: sellerID}}, 1, r.next, nil
+}
+
+func TestCreateSellerOwner(t *testing.T) {
+	sellers := &fakeSellerRepository{sellers: map[string]*api.Seller{}}
+	s := NewSellerService(sellers)
+
+	ctx := auth.NewContext(context.Background(), &auth.Principal{UserID: "alice", Role: auth.RoleUser})
+	resp, err := s.CreateSeller(ctx, &api.CreateSellerRequest{Seller: &api.Seller{Id: "chosen", Name: "Acme", OwnerId: "bob"}})
+	if err != nil {
+		t.Fatalf("CreateSeller() = %v", err)
+	}
+
+	seller := resp.GetSeller()
+	if seller.GetOwnerId() != "alice" {
+		t.Errorf("owner = %q, want the caller alice", seller.GetOwnerId())
+	}
+	if seller.GetId() == "" || seller.GetId() == "chosen" {
+		t.Errorf("id = %q, want one assigned by the server", seller.GetId())
+	}
+
+	_, err = s.CreateSeller(context.Background(), &api.CreateSellerRequest{Seller: &api.Seller{Name: "Acme"}})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("CreateSeller() without a principal = %v, want Unauthenticated", err)
+	}
+}
+
+func TestListSellerItems(t *testing.T) {
+	sellers := &fakeSellerRepository{
+		sellers: map[string]*api.Seller{"seller-1": {Id: "seller-1"}},
+		next:    &query.Cursor{Key: "b", ID: "item-2"},
+	}
+	s := NewSellerService(sellers)
+
+	first, err := s.ListSellerItems(context.Background(), &api.ListSellerItemsRequest{SellerId: "seller-1", PageSize: 1})
+	if err != nil {
+		t.Fatalf("ListSellerItems() = %v", err)
+	}
+	if first.GetTotalCount() != 1 || len(first.GetItems()) != 1 || first.GetNextPageToken() == "" {
+		t.Fatalf("ListSellerItems() = %v", first)
+	}
+
+	if _, err := s.ListSellerItems(context.Background(), &api.ListSellerItemsRequest{SellerId: "seller-1", PageToken: first.GetNextPageToken()}); err != nil {
+		t.Fatalf("ListSellerItems() with a page token = %v", err)
+	}
+	if sellers.page.After == nil || *sellers.page.After != *sellers.next {
+		t.Errorf("page.After = %v, want %v", sellers.page.After, sellers.next)
+	}
+
+	_, err = s.ListSellerItems(context.Background(), &api.ListSellerItemsRequest{SellerId: "seller-1", PageToken: "garbage!"})
+	if !errors.Is(err, query.ErrInvalidPageToken) {
+		t.Errorf("ListSellerItems() with a bad token = %v, want ErrInvalidPageToken", err)
+	}
+
+	_, err = s.ListSellerItems(context.Background(), &api.ListSellerItemsRequest{SellerId: "seller-2"})
+	if !errors.Is(err, domainerr.NotFound) {
+		t.Errorf("ListSellerItems() of an unknown seller = %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/daffaromero/gorpc-template/protobuf/api"
+	"github.com/daffaromero/gorpc-template/repository"
+	"github.com/daffaromero/gorpc-template/repository/query"
+)
+
+type userService struct {
+	api.UnimplementedUserServiceServer
+	userRepository repository.UserRepository
+}
+
+func NewUserService(userRepository repository.UserRepository) api.UserServiceServer {
+	return &userService{userRepository: userRepository}
+}
+
+func (s *userService) CreateUser(ctx context.Context, req *api.CreateUserRequest) (*api.CreateUserResponse, error) {
+	user := req.GetUser()
+	user.Id = uuid.NewString()
+
+	createdUser, err := s.userRepository.CreateUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.CreateUserResponse{User: createdUser}, nil
+}
+
+func (s *userService) GetUser(ctx context.Context, req *api.GetUserRequest) (*api.GetUserResponse, error) {
+	user, err := s.userRepository.GetUser(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.GetUserResponse{User: user}, nil
+}
+
+func (s *userService) ListUsers(ctx context.Context, req *api.ListUsersRequest) (*api.ListUsersResponse, error) {
+	users, totalCount, err := s.userRepository.ListUsers(ctx, query.NewPage(req.GetPage(), req.GetPageSize()), req.GetShowDeleted())
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.ListUsersResponse{Users: users, TotalCount: totalCount}, nil
+}
+
+func (s *userService) UpdateUser(ctx context.Context, req *api.UpdateUserRequest) (*api.UpdateUserResponse, error) {
+	user, err := s.userRepository.UpdateUser(ctx, req.GetUser())
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.UpdateUserResponse{User: user}, nil
+}
+
+func (s *userService) DeleteUser(ctx context.Context, req *api.DeleteUserRequest) (*api.DeleteUserResponse, error) {
+	if err := s.userRepository.DeleteUser(ctx, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	return &api.DeleteUserResponse{Success: true}, nil
+}
+
+func (s *userService) RestoreUser(ctx context.Context, req *api.RestoreUserRequest) (*api.RestoreUserResponse, error) {
+	user, err := s.userRepository.RestoreUser(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.RestoreUserResponse{User: user}, nil
+}
@@ -22,6 +22,7 @@ var (
 		Path   string
 	}
 	vaultClient *vault.Client
+	vaultErr    error
 	vaultOnce   sync.Once
 )
 
@@ -98,30 +99,29 @@ func getVaultEnv(key string, failedSources *[]string) string {
 }
 
 func getVaultClient() (*vault.Client, error) {
-	var err error
 	vaultOnce.Do(func() {
 		if !isVaultConfigValid() {
-			err = fmt.Errorf("invalid vault configuration")
+			vaultErr = fmt.Errorf("invalid vault configuration")
 			return
 		}
 
 		vaultURL := fmt.Sprintf("http://%s:%s", vaultConfig.Host, vaultConfig.Port)
 		if !isVaultReachable(vaultURL) {
-			err = fmt.Errorf("vault is not reachable")
+			vaultErr = fmt.Errorf("vault is not reachable")
 			return
 		}
 
 		config := vault.DefaultConfig()
 		config.Address = vaultURL
 
-		vaultClient, err = vault.NewClient(config)
-		if err != nil {
+		vaultClient, vaultErr = vault.NewClient(config)
+		if vaultErr != nil {
 			return
 		}
 		vaultClient.SetToken(vaultConfig.Token)
 	})
 
-	return vaultClient, err
+	return vaultClient, vaultErr
 }
 
 func isVaultConfigValid() bool {
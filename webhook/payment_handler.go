@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	logs "github.com/daffaromero/gorpc-template/helper/logger"
+	"github.com/daffaromero/gorpc-template/payment"
+	"github.com/daffaromero/gorpc-template/repository"
+)
+
+// SignatureHeader carries the hex encoded HMAC-SHA256 of the request body.
+const SignatureHeader = "X-Signature"
+
+const maxBodySize = 64 << 10
+
+// PaymentNotification is the body of a payment webhook.
+type PaymentNotification struct {
+	EventID      string `json:"event_id"`
+	OrderID      string `json:"order_id"`
+	PaymentToken string `json:"payment_token"`
+	Status       string `json:"status"`
+}
+
+type paymentHandler struct {
+	orderRepository repository.OrderRepository
+	secret          []byte
+	logger          *logs.Log
+}
+
+// NewPaymentHandler returns a handler for payment provider webhooks. Each
+// notification must be signed with secret; notifications are applied once
+// per event ID and replays are acknowledged without changing the order.
+func NewPaymentHandler(orderRepository repository.OrderRepository, secret string) http.Handler {
+	return &paymentHandler{orderRepository: orderRepository, secret: []byte(secret), logger: logs.New("payment_webhook")}
+}
+
+func (h *paymentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		h.reject(w, http.StatusRequestEntityTooLarge, "", "body too large or unreadable: %v", err)
+		return
+	}
+
+	if !h.validSignature(body, r.Header.Get(SignatureHeader)) {
+		h.reject(w, http.StatusUnauthorized, "", "invalid signature")
+		return
+	}
+
+	var notification PaymentNotification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		h.reject(w, http.StatusBadRequest, "", "malformed body: %v", err)
+		return
+	}
+
+	status, ok := payment.ParseStatus(notification.Status)
+	if !ok {
+		h.reject(w, http.StatusBadRequest, notification.EventID, "unknown status %q", notification.Status)
+		return
+	}
+	if notification.EventID == "" || notification.PaymentToken == "" {
+		h.reject(w, http.StatusBadRequest, notification.EventID, "event id and payment token are required")
+		return
+	}
+	if _, err := uuid.Parse(notification.OrderID); err != nil {
+		h.reject(w, http.StatusBadRequest, notification.EventID, "invalid order id %q", notification.OrderID)
+		return
+	}
+
+	applied, err := h.orderRepository.ApplyPaymentNotification(r.Context(), notification.EventID, notification.OrderID, notification.PaymentToken, status)
+	switch {
+	case errors.Is(err, domainerr.NotFound):
+		h.reject(w, http.StatusNotFound, notification.EventID, "%v", err)
+		return
+	case errors.Is(err, payment.ErrTokenMismatch):
+		h.reject(w, http.StatusForbidden, notification.EventID, "%v", err)
+		return
+	case errors.Is(err, payment.ErrInvalidTransition):
+		h.reject(w, http.StatusConflict, notification.EventID, "%v", err)
+		return
+	case err != nil:
+		h.logger.Error("Failed to apply payment notification %s: %v", notification.EventID, err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	if !applied {
+		h.logger.Info("Ignored replayed payment notification %s for order %s", notification.EventID, notification.OrderID)
+	} else {
+		h.logger.Info("Applied payment notification %s: order %s is %s", notification.EventID, notification.OrderID, status)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *paymentHandler) validSignature(body []byte, signature string) bool {
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+func (h *paymentHandler) reject(w http.ResponseWriter, code int, eventID, reason string, args ...any) {
+	h.logger.Warn("Rejected payment notification %q: "+reason, append([]any{eventID}, args...)...)
+	http.Error(w, http.StatusText(code), code)
+}
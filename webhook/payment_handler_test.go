@@ -0,0 +1,158 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/daffaromero/gorpc-template/helper/domainerr"
+	"github.com/daffaromero/gorpc-template/payment"
+	"github.com/daffaromero/gorpc-template/repository"
+)
+
+const (
+	testSecret  = "webhook-secret"
+	testOrderID = "0191c5f2-7c1e-7d3a-9f00-000000000001"
+)
+
+// fakeOrderRepository applies each event ID once and fails with errs[status]
+// when set. Other methods are not used by the handler and panic.
+type fakeOrderRepository struct {
+	repository.OrderRepository
+	seen    map[string]bool
+	applied []payment.Status
+	errs    map[payment.Status]error
+}
+
+func (r *fakeOrderRepository) ApplyPaymentNotification(ctx context.Context, eventID, orderID, token string, status payment.Status) (bool, error) {
+	if err := r.errs[status]; err != nil {
+		return false, err
+	}
+	if r.seen[eventID] {
+		return false, nil
+	}
+	r.seen[eventID] = true
+	r.applied = append(r.applied, status)
+	return true, nil
+}
+
+func sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func notificationBody(t *testing.T, eventID, orderID, token, status string) []byte {
+	t.Helper()
+
+	body, err := json.Marshal(PaymentNotification{EventID: eventID, OrderID: orderID, PaymentToken: token, Status: status})
+	if err != nil {
+		t.Fatalf("json.Marshal() = %v", err)
+	}
+	return body
+}
+
+func TestPaymentHandler(t *testing.T) {
+	valid := notificationBody(t, "evt-1", testOrderID, "tok", "paid")
+	unknownStatus := notificationBody(t, "evt-1", testOrderID, "tok", "settled")
+	noEventID := notificationBody(t, "", testOrderID, "tok", "paid")
+	noToken := notificationBody(t, "evt-1", testOrderID, "", "paid")
+	badOrderID := notificationBody(t, "evt-1", "order-1", "tok", "paid")
+	tooLarge := []byte(strings.Repeat("a", maxBodySize+1))
+
+	tests := []struct {
+		name      string
+		method    string
+		body      []byte
+		signature string
+		want      int
+	}{
+		{"applied", http.MethodPost, valid, sign(valid), http.StatusNoContent},
+		{"wrong method", http.MethodGet, valid, sign(valid), http.StatusMethodNotAllowed},
+		{"missing signature", http.MethodPost, valid, "", http.StatusUnauthorized},
+		{"signature of another body", http.MethodPost, valid, sign([]byte("{}")), http.StatusUnauthorized},
+		{"signature not hex", http.MethodPost, valid, "zz", http.StatusUnauthorized},
+		{"body too large", http.MethodPost, tooLarge, sign(tooLarge), http.StatusRequestEntityTooLarge},
+		{"malformed body", http.MethodPost, []byte("{"), sign([]byte("{")), http.StatusBadRequest},
+		{"unknown status", http.MethodPost, unknownStatus, sign(unknownStatus), http.StatusBadRequest},
+		{"missing event id", http.MethodPost, noEventID, sign(noEventID), http.StatusBadRequest},
+		{"missing token", http.MethodPost, noToken, sign(noToken), http.StatusBadRequest},
+		{"order id not a uuid", http.MethodPost, badOrderID, sign(badOrderID), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := &fakeOrderRepository{seen: map[string]bool{}}
+			req := httptest.NewRequest(tt.method, "/webhooks/payments", strings.NewReader(string(tt.body)))
+			req.Header.Set(SignatureHeader, tt.signature)
+			rec := httptest.NewRecorder()
+
+			NewPaymentHandler(orders, testSecret).ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if applied := len(orders.applied) > 0; applied != (tt.want == http.StatusNoContent) {
+				t.Errorf("notification applied = %v", applied)
+			}
+		})
+	}
+}
+
+func TestPaymentHandlerReplay(t *testing.T) {
+	orders := &fakeOrderRepository{seen: map[string]bool{}}
+	handler := NewPaymentHandler(orders, testSecret)
+	body := notificationBody(t, "evt-1", testOrderID, "tok", "paid")
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/payments", strings.NewReader(string(body)))
+		req.Header.Set(SignatureHeader, sign(body))
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNoContent {
+			t.Errorf("delivery %d: status = %d, want %d", i+1, rec.Code, http.StatusNoContent)
+		}
+	}
+
+	if len(orders.applied) != 1 {
+		t.Errorf("applied %d times, want once", len(orders.applied))
+	}
+}
+
+func TestPaymentHandlerRepositoryErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"unknown order", domainerr.ResourceNotFound("order", testOrderID), http.StatusNotFound},
+		{"token mismatch", domainerr.ForResource(domainerr.PermissionDenied, "order", testOrderID, "was issued a different payment token").WithCause(payment.ErrTokenMismatch), http.StatusForbidden},
+		{"invalid transition", domainerr.ForResource(domainerr.FailedPrecondition, "order", testOrderID, "cannot move payment").WithCause(payment.Transition(payment.StatusFailed, payment.StatusPaid)), http.StatusConflict},
+		{"database down", errors.New("connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := &fakeOrderRepository{seen: map[string]bool{}, errs: map[payment.Status]error{payment.StatusPaid: tt.err}}
+			body := notificationBody(t, "evt-1", testOrderID, "tok", "paid")
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/payments", strings.NewReader(string(body)))
+			req.Header.Set(SignatureHeader, sign(body))
+			rec := httptest.NewRecorder()
+
+			NewPaymentHandler(orders, testSecret).ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}